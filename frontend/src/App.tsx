@@ -1,24 +1,32 @@
-import { useEffect, useState } from "react";
+import { useEffect, useRef, useState } from "react";
 import "./App.css";
 
 interface Task {
   id: number;
   title: string;
   description: string;
-  status: "pending" | "completed";
+  status: "pending" | "in_progress" | "blocked" | "completed" | "cancelled";
+}
+
+// Data of the events of GET /tasks/stream
+interface TaskChange {
+  task: Task;
 }
 
 function App() {
   const [tasks, setTasks] = useState<Task[]>([]);
   const [newTask, setNewTask] = useState({ title: "", description: "" });
   const [loading, setLoading] = useState(false);
+  // Key of the form submission, kept until the task is created so that retries reuse it
+  const idempotencyKey = useRef<string | null>(null);
 
   const fetchTasks = async () => {
     try {
       setLoading(true);
       const response = await fetch("http://localhost:8080/tasks");
       const data = await response.json();
-      setTasks(data.tasks.reverse());
+      // Oldest first, in the order of GET /tasks, so that streamed tasks are appended
+      setTasks(data.tasks);
     } catch (error) {
       console.error("Error fetching tasks:", error);
     } finally {
@@ -26,20 +34,43 @@ function App() {
     }
   };
 
+  const editTask = (task: typeof newTask) => {
+    // A changed task is a new submission
+    idempotencyKey.current = null;
+    setNewTask(task);
+  };
+
   const createTask = async (e: React.FormEvent) => {
     e.preventDefault();
-    try {
-      const response = await fetch("http://localhost:8080/tasks", {
-        method: "POST",
-        headers: { "Content-Type": "application/json" },
-        body: JSON.stringify(newTask),
-      });
-      if (response.ok) {
-        setNewTask({ title: "", description: "" });
-        fetchTasks();
+    // Retries of this submission replay the response instead of creating a duplicate
+    idempotencyKey.current ??= crypto.randomUUID();
+    const key = idempotencyKey.current;
+
+    for (let attempt = 1; attempt <= 3; attempt++) {
+      try {
+        const response = await fetch("http://localhost:8080/tasks", {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            "Idempotency-Key": key,
+          },
+          body: JSON.stringify(newTask),
+        });
+        // Server errors and requests still in progress may succeed when retried
+        if (response.status >= 500 || response.status === 409) {
+          throw new Error(`status ${response.status}`);
+        }
+        if (response.ok) {
+          idempotencyKey.current = null;
+          setNewTask({ title: "", description: "" });
+        }
+        return;
+      } catch (error) {
+        console.error(`Error creating task (attempt ${attempt}):`, error);
+        if (attempt < 3) {
+          await new Promise((resolve) => setTimeout(resolve, 500 * attempt));
+        }
       }
-    } catch (error) {
-      console.error("Error creating task:", error);
     }
   };
 
@@ -50,7 +81,6 @@ function App() {
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({ status: "completed" }),
       });
-      fetchTasks();
     } catch (error) {
       console.error("Error updating task:", error);
     }
@@ -58,6 +88,34 @@ function App() {
 
   useEffect(() => {
     fetchTasks();
+
+    // Apply changes made by other clients, where the server streams them; EventSource reconnects
+    // and resumes with Last-Event-ID
+    const stream = new EventSource("http://localhost:8080/tasks/stream");
+    stream.addEventListener("created", (event) => {
+      const { task }: TaskChange = JSON.parse(event.data);
+      setTasks((tasks) =>
+        tasks.some((t) => t.id === task.id) ? tasks : [...tasks, task],
+      );
+    });
+    stream.addEventListener("updated", (event) => {
+      const { task }: TaskChange = JSON.parse(event.data);
+      setTasks((tasks) => tasks.map((t) => (t.id === task.id ? task : t)));
+    });
+    stream.addEventListener("deleted", (event) => {
+      const { task }: TaskChange = JSON.parse(event.data);
+      setTasks((tasks) => tasks.filter((t) => t.id !== task.id));
+    });
+    // Changes were missed while disconnected
+    stream.addEventListener("reset", () => fetchTasks());
+    // EventSource gives up on servers without the stream; show their changes at least once
+    stream.onerror = () => {
+      if (stream.readyState === EventSource.CLOSED) {
+        fetchTasks();
+      }
+    };
+
+    return () => stream.close();
   }, []);
 
   return (
@@ -69,7 +127,7 @@ function App() {
           type="text"
           placeholder="Task title"
           value={newTask.title}
-          onChange={(e) => setNewTask({ ...newTask, title: e.target.value })}
+          onChange={(e) => editTask({ ...newTask, title: e.target.value })}
           required
         />
         <input
@@ -77,7 +135,7 @@ function App() {
           placeholder="Task description"
           value={newTask.description}
           onChange={(e) =>
-            setNewTask({ ...newTask, description: e.target.value })
+            editTask({ ...newTask, description: e.target.value })
           }
         />
         <button type="submit">Add Task</button>
@@ -87,8 +145,8 @@ function App() {
         <p>Loading tasks...</p>
       ) : (
         <div className="tasks-list">
-          {tasks.map((task, index) => (
-            <div key={index} className={`task-item ${task.status}`}>
+          {tasks.map((task) => (
+            <div key={task.id} className={`task-item ${task.status}`}>
               <div className="task-content">
                 <h3>{task.title}</h3>
                 <p>{task.description}</p>
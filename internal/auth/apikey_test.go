@@ -0,0 +1,80 @@
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+)
+
+// memoryAPIKeyStore keeps API keys in memory
+type memoryAPIKeyStore struct {
+	keys    map[string]auth.APIKey
+	touched map[int]time.Time
+}
+
+func (s *memoryAPIKeyStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (auth.APIKey, error) {
+	key, ok := s.keys[prefix]
+	if !ok {
+		return auth.APIKey{}, fmt.Errorf("%w: unknown key", auth.ErrInvalidAPIKey)
+	}
+	return key, nil
+}
+
+func (s *memoryAPIKeyStore) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	s.touched[id] = usedAt
+	return nil
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	// Create dependencies
+	store := &memoryAPIKeyStore{keys: map[string]auth.APIKey{}, touched: map[int]time.Time{}}
+	authenticator := auth.NewAPIKeyAuthenticator(store)
+
+	active, activePrefix, activeHash := auth.GenerateAPIKey()
+	store.keys[activePrefix] = auth.APIKey{ID: 1, Username: "cron", WorkspaceID: 2, Scopes: []string{"tasks:read"}, Roles: []string{"admin"}, Hash: activeHash}
+
+	revokedAt := time.Now().Add(-time.Hour)
+	revoked, revokedPrefix, revokedHash := auth.GenerateAPIKey()
+	store.keys[revokedPrefix] = auth.APIKey{ID: 2, Username: "cron", Hash: revokedHash, RevokedAt: &revokedAt}
+
+	expiresAt := time.Now().Add(-time.Minute)
+	expired, expiredPrefix, expiredHash := auth.GenerateAPIKey()
+	store.keys[expiredPrefix] = auth.APIKey{ID: 3, Username: "cron", Hash: expiredHash, ExpiresAt: &expiresAt}
+
+	// A valid key yields a scoped principal with the key's roles and records its use
+	principal, err := authenticator.Authenticate(context.Background(), active)
+	require.NoError(t, err)
+	require.Equal(t, auth.Principal{Subject: "cron", WorkspaceID: 2, Scopes: []string{"tasks:read"}, Roles: []string{"admin"}, APIKeyID: 1}, principal)
+	require.Contains(t, store.touched, 1)
+
+	// Revoked, expired, forged and malformed keys are rejected
+	forged := active[:len(active)-1] + "0"
+	if forged == active {
+		forged = active[:len(active)-1] + "1"
+	}
+	for _, key := range []string{revoked, expired, forged, "tk_nope", "garbage"} {
+		_, err := authenticator.Authenticate(context.Background(), key)
+		require.ErrorIs(t, err, auth.ErrInvalidAPIKey, key)
+	}
+	require.NotContains(t, store.touched, 2)
+	require.NotContains(t, store.touched, 3)
+
+	// The middleware accepts the ApiKey scheme
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(auth.GinMiddleware(nil, authenticator))
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	request.Header.Set("Authorization", "ApiKey "+active)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
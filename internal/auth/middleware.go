@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errMissingCredentials is returned when a protected route is called without supported credentials
+var errMissingCredentials = errors.New("missing credentials")
+
+// Authenticator validates the credentials of one Authorization scheme
+type Authenticator interface {
+	Scheme() string
+	Authenticate(ctx context.Context, credentials string) (Principal, error)
+}
+
+// GinMiddleware authenticates requests with any of the authenticators, selected by the
+// scheme of the Authorization header, and puts the principal on the request context.
+// Public routes and unmatched paths are let through.
+func GinMiddleware(publicRoutes []string, authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" || slices.Contains(publicRoutes, route) {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticate(c.Request.Context(), authenticators, c.GetHeader("Authorization"))
+		if err != nil {
+			_ = c.Error(err)
+			if !unauthenticated(err) {
+				problem.Write(c.Writer, c.Request, problem.New(http.StatusInternalServerError, "failed to authenticate the request"))
+				c.Abort()
+				return
+			}
+
+			for _, authenticator := range authenticators {
+				c.Writer.Header().Add("WWW-Authenticate", authenticator.Scheme())
+			}
+			problem.Write(c.Writer, c.Request, problem.New(http.StatusUnauthorized, err.Error()))
+			c.Abort()
+			return
+		}
+
+		ctx := WithPrincipal(c.Request.Context(), principal)
+		ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With(slog.String("user", principal.Subject)))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("enduser.id", principal.Subject))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func authenticate(ctx context.Context, authenticators []Authenticator, header string) (Principal, error) {
+	scheme, credentials, found := strings.Cut(header, " ")
+	if !found || credentials == "" {
+		return Principal{}, errMissingCredentials
+	}
+
+	for _, authenticator := range authenticators {
+		if strings.EqualFold(scheme, authenticator.Scheme()) {
+			return authenticator.Authenticate(ctx, credentials)
+		}
+	}
+	return Principal{}, errMissingCredentials
+}
+
+// unauthenticated reports whether the error is caused by the caller's credentials
+func unauthenticated(err error) bool {
+	return errors.Is(err, errMissingCredentials) || errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrInvalidAPIKey)
+}
@@ -0,0 +1,60 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	// Create dependencies
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: secret})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(auth.GinMiddleware([]string{"/health"}, verifier))
+
+	var subject string
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/tasks", func(c *gin.Context) {
+		principal, _ := auth.PrincipalFromContext(c.Request.Context())
+		subject = principal.Subject
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path, authorization string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Public routes do not need a token
+	require.Equal(t, http.StatusOK, serve("/health", "").Code)
+
+	// Protected routes reject missing and invalid tokens with problem details
+	for _, authorization := range []string{"", "Basic YWxpY2U6c2VjcmV0", "Bearer nope"} {
+		recorder := serve("/tasks", authorization)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+		require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Bearer")
+	}
+	require.Empty(t, subject)
+
+	// A valid token exposes the principal to handlers
+	token := sign(t, jwt.SigningMethodHS256, []byte(secret), "", claimsFor("alice", time.Hour))
+	require.Equal(t, http.StatusOK, serve("/tasks", "Bearer "+token).Code)
+	require.Equal(t, "alice", subject)
+}
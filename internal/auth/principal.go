@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// RoleAdmin grants access to every user's resources
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Roles   []string
+	// WorkspaceID is the workspace the token is pinned to, or 0 when any of the user's workspaces may be selected
+	WorkspaceID int
+	// Scopes restrict what an API key may do on top of the roles; nil for tokens, which are not restricted
+	Scopes []string
+	// APIKeyID identifies the API key the caller authenticated with, or 0 for tokens
+	APIKeyID int
+}
+
+// HasRole reports whether the principal was granted the role
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired or not signed by a trusted key
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the JWT claims understood by the verifier
+type Claims struct {
+	Roles []string `json:"roles,omitempty"`
+	// WorkspaceID pins the token to one workspace
+	WorkspaceID int `json:"workspace_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Verifier validates HS256 and RS256 bearer tokens against the configured keys
+type Verifier struct {
+	parser *jwt.Parser
+	// Keys are indexed by their kid; keys from hmacSecret and rsaPublicKeyFile have an empty kid
+	hmacKeys map[string][]byte
+	rsaKeys  map[string]*rsa.PublicKey
+}
+
+// NewVerifier loads the verification keys from the configuration
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	if !cfg.HasKeys() {
+		return nil, errors.New("no verification key configured: set auth.hmacSecret, auth.rsaPublicKeyFile or auth.jwksFile, or disable auth")
+	}
+
+	verifier := Verifier{
+		hmacKeys: map[string][]byte{},
+		rsaKeys:  map[string]*rsa.PublicKey{},
+	}
+
+	if cfg.HMACSecret != "" {
+		verifier.hmacKeys[""] = []byte(cfg.HMACSecret)
+	}
+
+	if cfg.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSA public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+		}
+		verifier.rsaKeys[""] = key
+	}
+
+	if cfg.JWKSFile != "" {
+		if err := verifier.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, fmt.Errorf("failed to load JWKS: %w", err)
+		}
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	verifier.parser = jwt.NewParser(options...)
+
+	return &verifier, nil
+}
+
+func (v *Verifier) Scheme() string {
+	return "Bearer"
+}
+
+func (v *Verifier) Authenticate(ctx context.Context, token string) (Principal, error) {
+	return v.Verify(token)
+}
+
+// Verify validates the token and returns the principal it identifies
+func (v *Verifier) Verify(token string) (Principal, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return Principal{
+		Subject: claims.Subject,
+		Roles:   claims.Roles,
+
+		WorkspaceID: claims.WorkspaceID,
+	}, nil
+}
+
+// key selects the verification key matching the token's algorithm and kid
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return lookupKey(v.hmacKeys, kid)
+	case jwt.SigningMethodRS256.Alg():
+		return lookupKey(v.rsaKeys, kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+func lookupKey[K any](keys map[string]K, kid string) (any, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// A token without kid may use the only key available
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no key found for kid %q", kid)
+}
+
+// jwk is the subset of a JSON Web Key needed for RSA and symmetric keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+func (v *Verifier) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err := parseRSAKey(key)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key.Kid, err)
+			}
+			v.rsaKeys[key.Kid] = publicKey
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil {
+				return fmt.Errorf("key %q: invalid k: %w", key.Kid, err)
+			}
+			if len(secret) < config.MinHMACKeyLength {
+				return fmt.Errorf("key %q: k must be at least %d bytes", key.Kid, config.MinHMACKeyLength)
+			}
+			v.hmacKeys[key.Kid] = secret
+		}
+	}
+
+	if len(v.rsaKeys) == 0 && len(v.hmacKeys) == 0 {
+		return fmt.Errorf("%s contains no usable signing keys", path)
+	}
+	return nil
+}
+
+func parseRSAKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid e: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims auth.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func claimsFor(subject string, expiresIn time.Duration) auth.Claims {
+	return auth.Claims{
+		Roles: []string{"admin"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    "tasks",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+	}
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	// Create dependencies
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: secret, Issuer: "tasks"})
+	require.NoError(t, err)
+
+	// A valid token yields its principal
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "", claimsFor("alice", time.Hour)))
+	require.NoError(t, err)
+	require.Equal(t, "alice", principal.Subject)
+	require.True(t, principal.HasRole("admin"))
+
+	// Expired, forged, unsigned and foreign tokens are rejected
+	expired := sign(t, jwt.SigningMethodHS256, []byte(secret), "", claimsFor("alice", -time.Hour))
+	forged := sign(t, jwt.SigningMethodHS256, []byte("another secret of at least 32 bytes"), "", claimsFor("alice", time.Hour))
+	unsigned := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claimsFor("alice", time.Hour))
+	foreignClaims := claimsFor("alice", time.Hour)
+	foreignClaims.Issuer = "elsewhere"
+	foreign := sign(t, jwt.SigningMethodHS256, []byte(secret), "", foreignClaims)
+
+	for _, token := range []string{expired, forged, unsigned, foreign, "not a token"} {
+		_, err := verifier.Verify(token)
+		require.ErrorIs(t, err, auth.ErrInvalidToken)
+	}
+}
+
+func TestVerifier_RS256FromJWKS(t *testing.T) {
+	// Create dependencies
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{JWKSFile: path})
+	require.NoError(t, err)
+
+	// The key is selected by kid
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, key, "key-1", claimsFor("bob", time.Hour)))
+	require.NoError(t, err)
+	require.Equal(t, "bob", principal.Subject)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, key, "key-2", claimsFor("bob", time.Hour)))
+	require.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	// An HS256 token cannot be verified with the RSA public key
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "key-1", claimsFor("bob", time.Hour)))
+	require.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestNewVerifier_ShortJWKSSecret(t *testing.T) {
+	for _, k := range []string{"", "c2hvcnQ"} {
+		jwks, err := json.Marshal(map[string]any{
+			"keys": []map[string]string{{"kty": "oct", "kid": "key-1", "k": k}},
+		})
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+		_, err = auth.NewVerifier(config.AuthConfig{JWKSFile: path})
+		require.ErrorContains(t, err, "k must be at least 32 bytes")
+	}
+}
+
+func TestNewVerifier_RequiresKeys(t *testing.T) {
+	_, err := auth.NewVerifier(config.AuthConfig{})
+	require.ErrorContains(t, err, "no verification key configured")
+}
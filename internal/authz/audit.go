@@ -0,0 +1,41 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+)
+
+// AuditingAuthorizer writes an audit record for every denied authorization
+type AuditingAuthorizer struct {
+	authorizer Authorizer
+}
+
+func NewAuditingAuthorizer(authorizer Authorizer) Authorizer {
+	auditing := AuditingAuthorizer{
+		authorizer: authorizer,
+	}
+
+	return &auditing
+}
+
+func (a *AuditingAuthorizer) Allows(principal auth.Principal, action Action, resource Resource) bool {
+	return a.authorizer.Allows(principal, action, resource)
+}
+
+func (a *AuditingAuthorizer) Authorize(ctx context.Context, principal auth.Principal, action Action, resource Resource) error {
+	err := a.authorizer.Authorize(ctx, principal, action, resource)
+	if errors.Is(err, ErrForbidden) {
+		logging.FromContext(ctx).Warn("Authorization denied",
+			slog.Bool("audit", true),
+			slog.String("subject", principal.Subject),
+			slog.Any("roles", principal.Roles),
+			slog.String("action", string(action)),
+			slog.Bool("owned", resource.Owned),
+		)
+	}
+	return err
+}
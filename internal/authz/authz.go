@@ -0,0 +1,49 @@
+package authz
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sumup/dependency-injection-go/internal/auth"
+)
+
+// ErrForbidden is returned when the caller is not allowed to perform an action
+var ErrForbidden = errors.New("forbidden")
+
+// Action is an operation subject to authorization
+type Action string
+
+const (
+	ActionListTasks  Action = "tasks:list"
+	ActionCreateTask Action = "tasks:create"
+	ActionUpdateTask Action = "tasks:update"
+	// ActionAuditTasks reads the history of every task in the workspace
+	ActionAuditTasks Action = "tasks:audit"
+	// ActionManageWebhooks registers webhooks of the workspace and reads their deliveries
+	ActionManageWebhooks Action = "webhooks:manage"
+)
+
+// Actions lists every action policies can refer to
+var Actions = []Action{ActionListTasks, ActionCreateTask, ActionUpdateTask, ActionAuditTasks, ActionManageWebhooks}
+
+// Scopes map the scopes an API key can be granted to the actions they cover
+var Scopes = map[string][]Action{
+	"tasks:read":      {ActionListTasks},
+	"tasks:write":     {ActionCreateTask, ActionUpdateTask},
+	"tasks:audit":     {ActionAuditTasks},
+	"webhooks:manage": {ActionManageWebhooks},
+}
+
+// Resource describes what the action applies to
+type Resource struct {
+	// Owned is true when the resource belongs to the caller
+	Owned bool
+}
+
+// Authorizer decides whether a principal may perform an action
+type Authorizer interface {
+	// Allows reports whether the action is allowed, without side effects
+	Allows(principal auth.Principal, action Action, resource Resource) bool
+	// Authorize returns ErrForbidden, wrapped, when the action is not allowed
+	Authorize(ctx context.Context, principal auth.Principal, action Action, resource Resource) error
+}
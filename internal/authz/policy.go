@@ -0,0 +1,102 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// ownSuffix restricts a permission to the caller's own resources
+const ownSuffix = ":own"
+
+// wildcard grants every action
+const wildcard = "*"
+
+// permission grants an action, possibly only on owned resources
+type permission struct {
+	action  Action
+	ownOnly bool
+}
+
+// PolicyAuthorizer grants the permissions configured for each of the principal's roles
+type PolicyAuthorizer struct {
+	defaultRole string
+	policies    map[string][]permission
+}
+
+// NewPolicyAuthorizer parses the policies of the configuration
+func NewPolicyAuthorizer(cfg config.AuthzConfig) (*PolicyAuthorizer, error) {
+	authorizer := PolicyAuthorizer{
+		defaultRole: cfg.DefaultRole,
+		policies:    map[string][]permission{},
+	}
+
+	for role, grants := range cfg.Policies {
+		for _, grant := range grants {
+			parsed, err := parsePermission(grant)
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy for role %s: %w", role, err)
+			}
+			authorizer.policies[role] = append(authorizer.policies[role], parsed...)
+		}
+	}
+
+	return &authorizer, nil
+}
+
+func parsePermission(grant string) ([]permission, error) {
+	if grant == wildcard {
+		var all []permission
+		for _, action := range Actions {
+			all = append(all, permission{action: action})
+		}
+		return all, nil
+	}
+
+	action, ownOnly := strings.CutSuffix(grant, ownSuffix)
+	if !slices.Contains(Actions, Action(action)) {
+		return nil, fmt.Errorf("unknown permission %q", grant)
+	}
+	return []permission{{action: Action(action), ownOnly: ownOnly}}, nil
+}
+
+func (a *PolicyAuthorizer) Allows(principal auth.Principal, action Action, resource Resource) bool {
+	// Scoped principals, such as API keys, are limited to their scopes whatever their roles
+	if principal.Scopes != nil && !scopesCover(principal.Scopes, action) {
+		return false
+	}
+
+	roles := principal.Roles
+	if len(roles) == 0 && a.defaultRole != "" {
+		roles = []string{a.defaultRole}
+	}
+
+	for _, role := range roles {
+		for _, granted := range a.policies[role] {
+			if granted.action == action && (!granted.ownOnly || resource.Owned) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func scopesCover(scopes []string, action Action) bool {
+	for _, scope := range scopes {
+		if slices.Contains(Scopes[scope], action) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *PolicyAuthorizer) Authorize(ctx context.Context, principal auth.Principal, action Action, resource Resource) error {
+	if !a.Allows(principal, action, resource) {
+		return fmt.Errorf("%w: %s may not perform %s", ErrForbidden, principal.Subject, action)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package authz_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+)
+
+func TestPolicyAuthorizer_DefaultPolicies(t *testing.T) {
+	// Create dependencies
+	authorizer, err := authz.NewPolicyAuthorizer(config.Default().Authz)
+	require.NoError(t, err)
+
+	viewer := auth.Principal{Subject: "vera", Roles: []string{"viewer"}}
+	member := auth.Principal{Subject: "mike"}
+	admin := auth.Principal{Subject: "ada", Roles: []string{"admin"}}
+
+	own := authz.Resource{Owned: true}
+	other := authz.Resource{Owned: false}
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		action    authz.Action
+		resource  authz.Resource
+		allowed   bool
+	}{
+		{"viewer lists every task", viewer, authz.ActionListTasks, other, true},
+		{"viewer cannot create", viewer, authz.ActionCreateTask, own, false},
+		{"viewer cannot update", viewer, authz.ActionUpdateTask, own, false},
+		{"member lists own tasks", member, authz.ActionListTasks, own, true},
+		{"member cannot list every task", member, authz.ActionListTasks, other, false},
+		{"member creates", member, authz.ActionCreateTask, own, true},
+		{"member updates own task", member, authz.ActionUpdateTask, own, true},
+		{"member cannot update other task", member, authz.ActionUpdateTask, other, false},
+		{"admin updates any task", admin, authz.ActionUpdateTask, other, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.allowed, authorizer.Allows(tt.principal, tt.action, tt.resource))
+
+			err := authorizer.Authorize(context.Background(), tt.principal, tt.action, tt.resource)
+			if tt.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, authz.ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestNewPolicyAuthorizer_UnknownPermission(t *testing.T) {
+	_, err := authz.NewPolicyAuthorizer(config.AuthzConfig{
+		Policies: map[string][]string{"member": {"tasks:delete"}},
+	})
+	require.ErrorContains(t, err, `unknown permission "tasks:delete"`)
+}
+
+func TestAuditingAuthorizer_LogsDenials(t *testing.T) {
+	// Create dependencies
+	policies, err := authz.NewPolicyAuthorizer(config.Default().Authz)
+	require.NoError(t, err)
+	authorizer := authz.NewAuditingAuthorizer(policies)
+
+	var output bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), logging.New(config.LogConfig{Level: "info", Format: "text"}, &output))
+	viewer := auth.Principal{Subject: "vera", Roles: []string{"viewer"}}
+
+	// Allowed actions are not audited
+	require.NoError(t, authorizer.Authorize(ctx, viewer, authz.ActionListTasks, authz.Resource{}))
+	require.Empty(t, output.String())
+
+	// Denials are
+	require.ErrorIs(t, authorizer.Authorize(ctx, viewer, authz.ActionCreateTask, authz.Resource{Owned: true}), authz.ErrForbidden)
+	require.Contains(t, output.String(), "audit=true")
+	require.Contains(t, output.String(), "subject=vera")
+	require.Contains(t, output.String(), "action=tasks:create")
+}
+
+func TestPolicyAuthorizer_Scopes(t *testing.T) {
+	// Create dependencies
+	authorizer, err := authz.NewPolicyAuthorizer(config.Default().Authz)
+	require.NoError(t, err)
+
+	reader := auth.Principal{Subject: "cron", Scopes: []string{"tasks:read"}}
+	writer := auth.Principal{Subject: "cron", Scopes: []string{"tasks:read", "tasks:write"}}
+	own := authz.Resource{Owned: true}
+
+	// Scopes restrict API keys on top of the role policies
+	require.True(t, authorizer.Allows(reader, authz.ActionListTasks, own))
+	require.False(t, authorizer.Allows(reader, authz.ActionCreateTask, own))
+	require.True(t, authorizer.Allows(writer, authz.ActionCreateTask, own))
+
+	// but never grant more than the role does
+	require.False(t, authorizer.Allows(writer, authz.ActionUpdateTask, authz.Resource{Owned: false}))
+}
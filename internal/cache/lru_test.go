@@ -0,0 +1,55 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/cache"
+)
+
+func TestLRU(t *testing.T) {
+	// Create dependencies
+	lru := cache.NewLRU[string, int](2, time.Minute)
+
+	// Values are returned until evicted
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	value, ok := lru.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	// The least recently used entry is evicted once full
+	lru.Set("c", 3)
+	_, ok = lru.Get("b")
+	require.False(t, ok)
+	_, ok = lru.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 2, lru.Len())
+
+	// Setting an existing key replaces its value
+	lru.Set("c", 4)
+	value, _ = lru.Get("c")
+	require.Equal(t, 4, value)
+
+	// Matching entries can be removed together
+	lru.DeleteFunc(func(key string) bool { return key == "a" })
+	_, ok = lru.Get("a")
+	require.False(t, ok)
+	lru.Clear()
+	require.Zero(t, lru.Len())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	// Create dependencies
+	lru := cache.NewLRU[string, int](10, 10*time.Millisecond)
+	lru.Set("a", 1)
+
+	// Entries expire after the TTL
+	_, ok := lru.Get("a")
+	require.True(t, ok)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = lru.Get("a")
+	require.False(t, ok)
+	require.Zero(t, lru.Len())
+}
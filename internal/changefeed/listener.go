@@ -0,0 +1,151 @@
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/events"
+)
+
+// ConnectFunc opens the dedicated connection of a listener; pooled connections cannot be used
+// because they are not held for long enough to receive notifications
+type ConnectFunc func(ctx context.Context) (*pgx.Conn, error)
+
+// Handler receives the payload of a notification
+type Handler func(ctx context.Context, payload string)
+
+// ResetFunc is called whenever the listener (re)connects
+type ResetFunc func(ctx context.Context)
+
+// Listener passes the notifications of a Postgres channel to local subscribers
+type Listener struct {
+	connect ConnectFunc
+	channel string
+	config  config.ChangeFeedConfig
+	logger  *slog.Logger
+
+	mutex    sync.RWMutex
+	handlers []Handler
+	resets   []ResetFunc
+}
+
+func NewListener(connect ConnectFunc, channel string, cfg config.ChangeFeedConfig, logger *slog.Logger) *Listener {
+	listener := Listener{
+		connect: connect,
+		channel: channel,
+		config:  cfg,
+		logger:  logger.With(slog.String("channel", channel)),
+	}
+
+	return &listener
+}
+
+// Subscribe registers a handler for every notification. Handlers run one at a time, in the
+// order they subscribed.
+func (l *Listener) Subscribe(handler Handler) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.handlers = append(l.handlers, handler)
+}
+
+// OnReset registers a function called whenever the listener (re)connects. Notifications sent
+// while it was disconnected are lost, so subscribers must drop whatever they derived from
+// earlier ones.
+func (l *Listener) OnReset(reset ResetFunc) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.resets = append(l.resets, reset)
+}
+
+// Run listens until the context is cancelled, reconnecting with backoff whenever the connection fails
+func (l *Listener) Run(ctx context.Context) {
+	attempt := 0
+	for {
+		err := l.listen(ctx, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return
+		}
+
+		attempt++
+		delay := events.Backoff(l.config.ReconnectBackoff, l.config.MaxReconnectBackoff, attempt)
+		l.logger.Warn("Change feed disconnected, reconnecting", slog.Any("error", err), slog.Duration("delay", delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// listen connects, calls connected once listening, and passes notifications on until the connection fails
+func (l *Listener) listen(ctx context.Context, connected func()) error {
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	connected()
+	l.logger.Info("Listening for changes")
+	l.reset(ctx)
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, l.config.PingInterval)
+		notification, err := conn.WaitForNotification(waitCtx)
+		cancel()
+		switch {
+		case err == nil:
+			l.dispatch(ctx, notification.Payload)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case pgconn.Timeout(err):
+			// Nothing arrived in a while; a connection dropped without a reset would wait forever
+			if err := conn.Ping(ctx); err != nil {
+				return fmt.Errorf("failed to ping: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+	}
+}
+
+func (l *Listener) dispatch(ctx context.Context, payload string) {
+	l.mutex.RLock()
+	handlers := l.handlers
+	l.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		call(ctx, l.logger, func() { handler(ctx, payload) })
+	}
+}
+
+func (l *Listener) reset(ctx context.Context) {
+	l.mutex.RLock()
+	resets := l.resets
+	l.mutex.RUnlock()
+
+	for _, reset := range resets {
+		call(ctx, l.logger, func() { reset(ctx) })
+	}
+}
+
+// call keeps a panicking subscriber from stopping the listener
+func call(ctx context.Context, logger *slog.Logger, fn func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.ErrorContext(ctx, "Change feed subscriber panicked", slog.Any("panic", p))
+		}
+	}()
+	fn()
+}
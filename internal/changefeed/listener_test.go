@@ -0,0 +1,53 @@
+package changefeed_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/changefeed"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+func TestListener_Run(t *testing.T) {
+	// Create dependencies
+	ctx, cancel := context.WithCancel(t.Context())
+	var attempts atomic.Int32
+	connect := func(ctx context.Context) (*pgx.Conn, error) {
+		// Stop once the listener has retried a few times
+		if attempts.Add(1) == 3 {
+			cancel()
+		}
+		return nil, errors.New("connection refused")
+	}
+	cfg := config.ChangeFeedConfig{
+		PingInterval:        time.Second,
+		ReconnectBackoff:    time.Millisecond,
+		MaxReconnectBackoff: 2 * time.Millisecond,
+	}
+	listener := changefeed.NewListener(connect, "task_changes", cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	resets := 0
+	listener.OnReset(func(ctx context.Context) { resets++ })
+
+	// Failed connections are retried until the context is cancelled
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.Run(ctx)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not stop")
+	}
+	require.Equal(t, int32(3), attempts.Load())
+
+	// Subscribers are only reset once the listener is connected
+	require.Zero(t, resets)
+}
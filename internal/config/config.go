@@ -0,0 +1,514 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the configuration file read when APP_CONFIG_FILE is not set.
+// It is optional: when it does not exist only defaults and environment variables apply.
+const DefaultFile = "config.yaml"
+
+// EnvPrefix is prepended to the env tag of every field when reading environment variables
+const EnvPrefix = "APP_"
+
+// MinHMACKeyLength is the shortest HS256 key accepted, from auth.hmacSecret or a JWKS file
+const MinHMACKeyLength = 32
+
+// Config holds every setting shared by the servers and the manage tool
+type Config struct {
+	HTTP        HTTPConfig        `yaml:"http"`
+	Database    DatabaseConfig    `yaml:"database"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Log         LogConfig         `yaml:"log"`
+	Health      HealthConfig      `yaml:"health"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Authz       AuthzConfig       `yaml:"authz"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Stream      StreamConfig      `yaml:"stream"`
+	ChangeFeed  ChangeFeedConfig  `yaml:"changeFeed"`
+	Cache       CacheConfig       `yaml:"cache"`
+
+	// File is the path of the YAML file the configuration was read from, if any
+	File string `yaml:"-"`
+}
+
+// HTTPConfig configures the HTTP server
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"HTTP_ADDR"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes" env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+}
+
+// DatabaseConfig configures the Postgres connection and pool
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslMode" env:"DB_SSLMODE"`
+	MaxConns        int32         `yaml:"maxConns" env:"DB_MAX_CONNS"`
+	MinConns        int32         `yaml:"minConns" env:"DB_MIN_CONNS"`
+	MaxConnLifetime time.Duration `yaml:"maxConnLifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" env:"DB_MAX_CONN_IDLE_TIME"`
+	ConnectTimeout  time.Duration `yaml:"connectTimeout" env:"DB_CONNECT_TIMEOUT"`
+}
+
+// CORSConfig configures the allowed cross-origin callers
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+// LogConfig configures logging
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// HealthConfig configures the readiness checks
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT"`
+	// ReplicaDSN enables the replica lag check when set
+	ReplicaDSN    string        `yaml:"replicaDsn" env:"HEALTH_REPLICA_DSN"`
+	MaxReplicaLag time.Duration `yaml:"maxReplicaLag" env:"HEALTH_MAX_REPLICA_LAG"`
+}
+
+// AuthConfig configures how bearer tokens are verified.
+// Tokens are signed with HS256 using HMACSecret, or with RS256 using the key in
+// RSAPublicKeyFile (PEM) or one of the keys in JWKSFile.
+type AuthConfig struct {
+	// Enabled requires a token on every non-public route; it is off by default because it needs a key.
+	// Without it every caller works in the default workspace, and the server is not ready while others exist.
+	Enabled          bool          `yaml:"enabled" env:"AUTH_ENABLED"`
+	HMACSecret       string        `yaml:"hmacSecret" env:"AUTH_HMAC_SECRET"`
+	RSAPublicKeyFile string        `yaml:"rsaPublicKeyFile" env:"AUTH_RSA_PUBLIC_KEY_FILE"`
+	JWKSFile         string        `yaml:"jwksFile" env:"AUTH_JWKS_FILE"`
+	Issuer           string        `yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience         string        `yaml:"audience" env:"AUTH_AUDIENCE"`
+	Leeway           time.Duration `yaml:"leeway" env:"AUTH_LEEWAY"`
+	// PublicRoutes are route patterns, such as /tasks/:id, that do not require a token
+	PublicRoutes []string `yaml:"publicRoutes" env:"AUTH_PUBLIC_ROUTES"`
+}
+
+// HasKeys reports whether any verification key is configured
+func (c AuthConfig) HasKeys() bool {
+	return c.HMACSecret != "" || c.RSAPublicKeyFile != "" || c.JWKSFile != ""
+}
+
+// AuthzConfig configures the permissions granted to each role.
+// Permissions are actions such as tasks:create, optionally suffixed with :own to
+// restrict them to the caller's tasks; * grants every action.
+type AuthzConfig struct {
+	// DefaultRole applies to callers whose token carries no role
+	DefaultRole string `yaml:"defaultRole" env:"AUTHZ_DEFAULT_ROLE"`
+	// Policies map roles to permissions. Roles set in YAML replace the default permissions of that role.
+	Policies map[string][]string `yaml:"policies"`
+}
+
+// RateLimitConfig configures the token buckets limiting each client
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// Store keeps the buckets in memory, or in Postgres to share them between instances
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	// Default applies to every route without its own limit
+	Default RateLimit `yaml:"default"`
+	// Routes override the default limit, keyed by method and route such as "POST /tasks"
+	Routes map[string]RateLimit `yaml:"routes"`
+	// Address applies to all routes together per client address, before authentication,
+	// so that requests with invalid credentials are limited too
+	Address RateLimit `yaml:"address" env:"-"`
+	// ExemptRoutes, such as health checks, are never limited
+	ExemptRoutes []string `yaml:"exemptRoutes" env:"RATE_LIMIT_EXEMPT_ROUTES"`
+}
+
+// RateLimit allows Requests per Period on average, with bursts of up to Burst requests
+type RateLimit struct {
+	Requests int           `yaml:"requests" env:"RATE_LIMIT_REQUESTS"`
+	Period   time.Duration `yaml:"period" env:"RATE_LIMIT_PERIOD"`
+	Burst    int           `yaml:"burst" env:"RATE_LIMIT_BURST"`
+}
+
+// IdempotencyConfig configures how responses to requests with an Idempotency-Key are replayed
+type IdempotencyConfig struct {
+	Enabled bool `yaml:"enabled" env:"IDEMPOTENCY_ENABLED"`
+	// TTL is how long a key and its response are kept
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	// WaitTimeout is how long a duplicate waits for the first request before getting 409
+	WaitTimeout time.Duration `yaml:"waitTimeout" env:"IDEMPOTENCY_WAIT_TIMEOUT"`
+	// LockTimeout is how long an unfinished request holds its key before it is considered abandoned
+	LockTimeout time.Duration `yaml:"lockTimeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+}
+
+// OutboxConfig configures the dispatcher delivering domain events from the outbox to subscribers
+type OutboxConfig struct {
+	// Enabled runs the dispatcher; events are written to the outbox either way
+	Enabled bool `yaml:"enabled" env:"OUTBOX_ENABLED"`
+	// PollInterval is how often the outbox is checked for due events when it was found empty
+	PollInterval time.Duration `yaml:"pollInterval" env:"OUTBOX_POLL_INTERVAL"`
+	// MaxAttempts is how often delivery is attempted before an event is dead-lettered
+	MaxAttempts int `yaml:"maxAttempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	// RetryBackoff is the delay before the first retry; it doubles up to MaxRetryBackoff
+	RetryBackoff    time.Duration `yaml:"retryBackoff" env:"OUTBOX_RETRY_BACKOFF"`
+	MaxRetryBackoff time.Duration `yaml:"maxRetryBackoff" env:"OUTBOX_MAX_RETRY_BACKOFF"`
+}
+
+// WebhooksConfig configures the delivery of task events to registered webhook endpoints
+type WebhooksConfig struct {
+	// Enabled runs the sender; deliveries are queued either way
+	Enabled bool `yaml:"enabled" env:"WEBHOOKS_ENABLED"`
+	// Timeout bounds each delivery request
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT"`
+	// PollInterval is how often due deliveries are checked for when none was found
+	PollInterval time.Duration `yaml:"pollInterval" env:"WEBHOOKS_POLL_INTERVAL"`
+	// MaxAttempts is how often a delivery is attempted before it is marked failed
+	MaxAttempts int `yaml:"maxAttempts" env:"WEBHOOKS_MAX_ATTEMPTS"`
+	// RetryBackoff is the delay before the first retry; it doubles up to MaxRetryBackoff
+	RetryBackoff    time.Duration `yaml:"retryBackoff" env:"WEBHOOKS_RETRY_BACKOFF"`
+	MaxRetryBackoff time.Duration `yaml:"maxRetryBackoff" env:"WEBHOOKS_MAX_RETRY_BACKOFF"`
+	// AllowPrivateAddresses lets deliveries reach loopback, link-local and private addresses,
+	// for receivers running next to the server during development. Keep it off where anyone may register endpoints.
+	AllowPrivateAddresses bool `yaml:"allowPrivateAddresses" env:"WEBHOOKS_ALLOW_PRIVATE_ADDRESSES"`
+}
+
+// StreamConfig configures the live task updates of GET /tasks/stream
+type StreamConfig struct {
+	// HeartbeatInterval is how often idle streams send a comment, so proxies keep them open
+	HeartbeatInterval time.Duration `yaml:"heartbeatInterval" env:"STREAM_HEARTBEAT_INTERVAL"`
+	// RetryInterval tells clients how long to wait before reconnecting
+	RetryInterval time.Duration `yaml:"retryInterval" env:"STREAM_RETRY_INTERVAL"`
+	// HistorySize is how many updates are retained for clients resuming with Last-Event-ID
+	HistorySize int `yaml:"historySize" env:"STREAM_HISTORY_SIZE"`
+	// BufferSize is how many updates a client may lag behind before it is disconnected
+	BufferSize int `yaml:"bufferSize" env:"STREAM_BUFFER_SIZE"`
+}
+
+// ChangeFeedConfig configures the listener for changes notified by Postgres, which keeps every
+// instance informed of the writes of the others
+type ChangeFeedConfig struct {
+	Enabled bool `yaml:"enabled" env:"CHANGE_FEED_ENABLED"`
+	// PingInterval is how long the listener waits for a notification before checking its connection
+	PingInterval time.Duration `yaml:"pingInterval" env:"CHANGE_FEED_PING_INTERVAL"`
+	// ReconnectBackoff is the delay before reconnecting; it doubles up to MaxReconnectBackoff
+	ReconnectBackoff    time.Duration `yaml:"reconnectBackoff" env:"CHANGE_FEED_RECONNECT_BACKOFF"`
+	MaxReconnectBackoff time.Duration `yaml:"maxReconnectBackoff" env:"CHANGE_FEED_MAX_RECONNECT_BACKOFF"`
+}
+
+// CacheConfig configures the cache of task reads
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled" env:"CACHE_ENABLED"`
+	// Size bounds the number of cached reads; the least recently used are evicted first
+	Size int `yaml:"size" env:"CACHE_SIZE"`
+	// TTL bounds how long a read is cached, and how stale it can be when an invalidation is missed
+	TTL time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	// InvalidateOnChanges also invalidates on the writes of other instances; it requires the change feed
+	InvalidateOnChanges bool `yaml:"invalidateOnChanges" env:"CACHE_INVALIDATE_ON_CHANGES"`
+}
+
+// TracingConfig configures where spans are exported
+type TracingConfig struct {
+	// Exporter is one of none, stdout, file or otlp
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// File receives the spans when the exporter is file
+	File string `yaml:"file" env:"TRACING_FILE"`
+	// Endpoint is the OTLP/HTTP collector URL when the exporter is otlp
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"serviceName" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5433,
+			User:            "postgres",
+			Password:        "postgres",
+			Name:            "postgres",
+			SSLMode:         "disable",
+			MaxConns:        10,
+			MinConns:        0,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Health: HealthConfig{
+			CheckTimeout:  2 * time.Second,
+			MaxReplicaLag: 30 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			File:        "traces.jsonl",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "tasks",
+			SampleRatio: 1,
+		},
+		Auth: AuthConfig{
+			Enabled:      false,
+			Leeway:       30 * time.Second,
+			PublicRoutes: []string{"/health", "/livez", "/readyz", "/metrics"},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "memory",
+			Default: RateLimit{Requests: 300, Period: time.Minute, Burst: 50},
+			Address: RateLimit{Requests: 600, Period: time.Minute, Burst: 100},
+			Routes: map[string]RateLimit{
+				"POST /tasks": {Requests: 30, Period: time.Minute, Burst: 10},
+			},
+			ExemptRoutes: []string{"/health", "/livez", "/readyz", "/metrics"},
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:     true,
+			TTL:         24 * time.Hour,
+			WaitTimeout: 10 * time.Second,
+			LockTimeout: time.Minute,
+		},
+		Outbox: OutboxConfig{
+			Enabled:         true,
+			PollInterval:    time.Second,
+			MaxAttempts:     10,
+			RetryBackoff:    time.Second,
+			MaxRetryBackoff: 10 * time.Minute,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:         true,
+			Timeout:         10 * time.Second,
+			PollInterval:    time.Second,
+			MaxAttempts:     8,
+			RetryBackoff:    10 * time.Second,
+			MaxRetryBackoff: time.Hour,
+		},
+		Stream: StreamConfig{
+			HeartbeatInterval: 15 * time.Second,
+			RetryInterval:     3 * time.Second,
+			HistorySize:       1000,
+			BufferSize:        64,
+		},
+		ChangeFeed: ChangeFeedConfig{
+			Enabled:             true,
+			PingInterval:        30 * time.Second,
+			ReconnectBackoff:    time.Second,
+			MaxReconnectBackoff: 30 * time.Second,
+		},
+		Cache: CacheConfig{
+			Enabled:             false,
+			Size:                1000,
+			TTL:                 30 * time.Second,
+			InvalidateOnChanges: true,
+		},
+		Authz: AuthzConfig{
+			DefaultRole: "member",
+			Policies: map[string][]string{
+				"viewer": {"tasks:list"},
+				"member": {"tasks:list:own", "tasks:create", "tasks:update:own"},
+				"admin":  {"*"},
+			},
+		},
+	}
+}
+
+// Load reads the configuration and validates it.
+// Values come from the defaults, then the YAML file, then environment variables, each overriding the previous.
+func Load() (Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Read reads the configuration without validating it
+func Read() (Config, error) {
+	cfg := Default()
+
+	path, required := os.LookupEnv(EnvPrefix + "CONFIG_FILE")
+	if !required {
+		path = DefaultFile
+	}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		cfg.File = path
+	case errors.Is(err, os.ErrNotExist) && !required:
+		// The default file is optional
+	default:
+		return Config{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := applyEnv(&cfg, os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks every setting and reports all problems at once
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, port, err := net.SplitHostPort(c.HTTP.Addr)
+	check(err == nil && port != "", "http.addr %q must be in host:port form", c.HTTP.Addr)
+	check(c.HTTP.ReadTimeout >= 0, "http.readTimeout must not be negative")
+	check(c.HTTP.ReadHeaderTimeout >= 0, "http.readHeaderTimeout must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "http.writeTimeout must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "http.idleTimeout must not be negative")
+	check(c.HTTP.MaxHeaderBytes > 0, "http.maxHeaderBytes must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdownTimeout must be positive")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port %d is out of range", c.Database.Port)
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.Name != "", "database.name is required")
+	check(oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		"database.sslMode %q is not a valid sslmode", c.Database.SSLMode)
+	check(c.Database.MaxConns > 0, "database.maxConns must be positive")
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+		"database.minConns must be between 0 and database.maxConns")
+	check(c.Database.MaxConnLifetime >= 0, "database.maxConnLifetime must not be negative")
+	check(c.Database.MaxConnIdleTime >= 0, "database.maxConnIdleTime must not be negative")
+	check(c.Database.ConnectTimeout >= 0, "database.connectTimeout must not be negative")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowedOrigins must not be empty")
+
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level %q must be one of debug, info, warn, error", c.Log.Level)
+	check(oneOf(c.Log.Format, "text", "json"), "log.format %q must be one of text, json", c.Log.Format)
+
+	check(c.Health.CheckTimeout > 0, "health.checkTimeout must be positive")
+	check(c.Health.MaxReplicaLag > 0, "health.maxReplicaLag must be positive")
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "file", "otlp"),
+		"tracing.exporter %q must be one of none, stdout, file, otlp", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file is required for the file exporter")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+	check(c.Tracing.ServiceName != "", "tracing.serviceName is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio must be between 0 and 1")
+
+	check(c.Auth.HMACSecret == "" || len(c.Auth.HMACSecret) >= MinHMACKeyLength, "auth.hmacSecret must be at least %d bytes", MinHMACKeyLength)
+	check(c.Auth.Leeway >= 0, "auth.leeway must not be negative")
+
+	check(oneOf(c.RateLimit.Store, "memory", "postgres"), "rateLimit.store %q must be one of memory, postgres", c.RateLimit.Store)
+	for route, limit := range c.RateLimit.Routes {
+		check(limit.Requests > 0 && limit.Period > 0 && limit.Burst > 0, "rateLimit.routes[%s] must have positive requests, period and burst", route)
+	}
+	check(c.RateLimit.Default.Requests > 0 && c.RateLimit.Default.Period > 0 && c.RateLimit.Default.Burst > 0,
+		"rateLimit.default must have positive requests, period and burst")
+	check(c.RateLimit.Address.Requests > 0 && c.RateLimit.Address.Period > 0 && c.RateLimit.Address.Burst > 0,
+		"rateLimit.address must have positive requests, period and burst")
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl must be positive")
+	check(c.Idempotency.WaitTimeout > 0, "idempotency.waitTimeout must be positive")
+	check(c.Idempotency.LockTimeout > 0, "idempotency.lockTimeout must be positive")
+
+	check(c.Outbox.PollInterval > 0, "outbox.pollInterval must be positive")
+	check(c.Outbox.MaxAttempts > 0, "outbox.maxAttempts must be positive")
+	check(c.Outbox.RetryBackoff > 0 && c.Outbox.MaxRetryBackoff >= c.Outbox.RetryBackoff,
+		"outbox.retryBackoff must be positive and at most outbox.maxRetryBackoff")
+
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.PollInterval > 0, "webhooks.pollInterval must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.maxAttempts must be positive")
+	check(c.Webhooks.RetryBackoff > 0 && c.Webhooks.MaxRetryBackoff >= c.Webhooks.RetryBackoff,
+		"webhooks.retryBackoff must be positive and at most webhooks.maxRetryBackoff")
+
+	check(c.Stream.HeartbeatInterval > 0, "stream.heartbeatInterval must be positive")
+	check(c.Stream.RetryInterval > 0, "stream.retryInterval must be positive")
+	check(c.Stream.HistorySize > 0, "stream.historySize must be positive")
+	check(c.Stream.BufferSize > 0, "stream.bufferSize must be positive")
+
+	check(c.ChangeFeed.PingInterval > 0, "changeFeed.pingInterval must be positive")
+	check(c.ChangeFeed.ReconnectBackoff > 0 && c.ChangeFeed.MaxReconnectBackoff >= c.ChangeFeed.ReconnectBackoff,
+		"changeFeed.reconnectBackoff must be positive and at most changeFeed.maxReconnectBackoff")
+
+	check(c.Cache.Size > 0, "cache.size must be positive")
+	check(c.Cache.TTL > 0, "cache.ttl must be positive")
+	check(!c.Cache.Enabled || !c.Cache.InvalidateOnChanges || c.ChangeFeed.Enabled,
+		"cache.invalidateOnChanges requires changeFeed.enabled")
+
+	_, defaultRoleExists := c.Authz.Policies[c.Authz.DefaultRole]
+	check(c.Authz.DefaultRole == "" || defaultRoleExists, "authz.defaultRole %q has no policy", c.Authz.DefaultRole)
+
+	return errors.Join(errs...)
+}
+
+// DSN returns the connection string for the database
+func (c DatabaseConfig) DSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// AllowOrigin returns the Access-Control-Allow-Origin value for the given request origin,
+// or false if the origin is not allowed
+func (c CORSConfig) AllowOrigin(origin string) (string, bool) {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if allowed == origin {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// AllowsAllOrigins reports whether any origin may call the API
+func (c CORSConfig) AllowsAllOrigins() bool {
+	return slices.Contains(c.AllowedOrigins, "*")
+}
+
+func oneOf(value string, allowed ...string) bool {
+	return slices.Contains(allowed, value)
+}
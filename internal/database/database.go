@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// PoolOption customizes the pool configuration before the pool is created
+type PoolOption func(poolConfig *pgxpool.Config)
+
+// WithQueryTracer traces every query sent through the pool
+func WithQueryTracer(tracer pgx.QueryTracer) PoolOption {
+	return func(poolConfig *pgxpool.Config) {
+		poolConfig.ConnConfig.Tracer = tracer
+	}
+}
+
+// WithQueryExecMode changes how queries are sent. QueryExecModeExec avoids preparing
+// statements, which suits queries whose text changes on every call.
+func WithQueryExecMode(mode pgx.QueryExecMode) PoolOption {
+	return func(poolConfig *pgxpool.Config) {
+		poolConfig.ConnConfig.DefaultQueryExecMode = mode
+	}
+}
+
+// AppRole is the role row-level security applies to. Tenant data is only read and written as
+// this role, even when the configured user is a superuser or owns the tables.
+const AppRole = "tasks_app"
+
+// WithWorkspace switches every connection of the pool to AppRole within the workspace, for
+// servers that serve a single workspace and do not select one per transaction
+func WithWorkspace(workspaceID int) PoolOption {
+	return func(poolConfig *pgxpool.Config) {
+		enterWorkspace(poolConfig.ConnConfig, workspaceID)
+	}
+}
+
+// enterWorkspace sets the role and workspace for the whole session, right after connecting
+func enterWorkspace(connConfig *pgx.ConnConfig, workspaceID int) {
+	query := `SELECT set_config('role', '` + AppRole + `', false), set_config('app.workspace_id', '` + strconv.Itoa(workspaceID) + `', false)`
+	connConfig.AfterConnect = func(ctx context.Context, conn *pgconn.PgConn) error {
+		if _, err := conn.Exec(ctx, query).ReadAll(); err != nil {
+			return fmt.Errorf("failed to enter workspace %d: %w", workspaceID, err)
+		}
+		return nil
+	}
+}
+
+// NewPool creates a connection pool from the database configuration
+func NewPool(ctx context.Context, cfg config.DatabaseConfig, options ...PoolOption) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	for _, option := range options {
+		option(poolConfig)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	return pool, nil
+}
+
+// Connect opens a single connection from the database configuration
+func Connect(ctx context.Context, cfg config.DatabaseConfig) (*pgx.Conn, error) {
+	connConfig, err := pgx.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection config: %w", err)
+	}
+	connConfig.ConnectTimeout = cfg.ConnectTimeout
+
+	return pgx.ConnectConfig(ctx, connConfig)
+}
+
+// ConnectToWorkspace opens a single connection as AppRole within the workspace, like WithWorkspace
+func ConnectToWorkspace(ctx context.Context, cfg config.DatabaseConfig, workspaceID int) (*pgx.Conn, error) {
+	connConfig, err := pgx.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection config: %w", err)
+	}
+	connConfig.ConnectTimeout = cfg.ConnectTimeout
+	enterWorkspace(connConfig, workspaceID)
+
+	return pgx.ConnectConfig(ctx, connConfig)
+}
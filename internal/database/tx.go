@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is implemented by both the pool and transactions, so repositories can run their queries on either
+type DBTX interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// TxManager runs units of work that span several repository calls
+type TxManager interface {
+	// WithinTx runs fn in a transaction and commits it if fn succeeds. Repositories called with the
+	// context passed to fn use the transaction. Nested calls run in a savepoint of the outer
+	// transaction. fn may run several times when the transaction is retried.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// WithTx returns a context whose repository calls use the transaction
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction of the unit of work the context belongs to, if any
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+type afterCommitKey struct{}
+
+// afterCommit collects the functions to run once the transaction of a unit of work commits
+type afterCommit struct {
+	mutex sync.Mutex
+	fns   []func()
+}
+
+// AfterCommit runs fn once the unit of work of the context commits, so that fn sees what other
+// readers see. Outside of a unit of work started by a TxManager it runs fn immediately. fn is
+// dropped when the transaction rolls back, but still runs when only a savepoint rolled back.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+func (a *afterCommit) run() {
+	a.mutex.Lock()
+	fns := a.fns
+	a.mutex.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Conn returns the transaction of the context, or the pool outside of a unit of work
+func Conn(ctx context.Context, pool *pgxpool.Pool) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return pool
+}
+
+// IsSerializationFailure reports whether the transaction failed because of concurrent
+// transactions and succeeds when retried
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// TxOption customizes the transactions of a TxManager
+type TxOption func(m *PoolTxManager)
+
+// WithIsolationLevel sets the isolation level of transactions
+func WithIsolationLevel(level pgx.TxIsoLevel) TxOption {
+	return func(m *PoolTxManager) {
+		m.options.IsoLevel = level
+	}
+}
+
+// WithMaxRetries sets how often a transaction is retried after serialization failures
+func WithMaxRetries(retries int) TxOption {
+	return func(m *PoolTxManager) {
+		m.maxRetries = retries
+	}
+}
+
+const (
+	// defaultMaxRetries is how often a transaction is retried unless configured otherwise
+	defaultMaxRetries = 3
+	// retryBackoff is the delay before the first retry; it doubles with every further retry
+	retryBackoff = 10 * time.Millisecond
+)
+
+// TxBeginner begins transactions; *pgxpool.Pool implements it
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// PoolTxManager begins transactions on a pool
+type PoolTxManager struct {
+	pool       TxBeginner
+	options    pgx.TxOptions
+	maxRetries int
+}
+
+func NewTxManager(pool TxBeginner, options ...TxOption) *PoolTxManager {
+	manager := PoolTxManager{
+		pool:       pool,
+		maxRetries: defaultMaxRetries,
+	}
+	for _, option := range options {
+		option(&manager)
+	}
+
+	return &manager
+}
+
+func (m *PoolTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Savepoints cannot be retried on their own; a serialization failure aborts the outer transaction
+	if tx, ok := TxFromContext(ctx); ok {
+		return pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+			return fn(WithTx(ctx, savepoint))
+		})
+	}
+
+	for attempt := 0; ; attempt++ {
+		// Every attempt collects its own functions; those of a failed attempt never run
+		hooks := &afterCommit{}
+		err := pgx.BeginTxFunc(ctx, m.pool, m.options, func(tx pgx.Tx) error {
+			return fn(WithTx(context.WithValue(ctx, afterCommitKey{}, hooks), tx))
+		})
+		if err == nil {
+			hooks.run()
+			return nil
+		}
+		if !IsSerializationFailure(err) || attempt >= m.maxRetries {
+			return err
+		}
+
+		// Back off with jitter so that the conflicting transactions do not collide again
+		backoff := retryBackoff << attempt
+		backoff += rand.N(backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction not retried: %w", errors.Join(err, ctx.Err()))
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/database"
+)
+
+// recordingDB hands out fake transactions and records what happens to them
+type recordingDB struct {
+	log []string
+}
+
+func (db *recordingDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	db.log = append(db.log, "begin")
+	return &recordingTx{db: db}, nil
+}
+
+// recordingTx is a transaction, or a savepoint when nested, that only records its outcome
+type recordingTx struct {
+	pgx.Tx
+	db     *recordingDB
+	nested bool
+	closed bool
+}
+
+func (tx *recordingTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx.db.log = append(tx.db.log, "savepoint")
+	return &recordingTx{db: tx.db, nested: true}, nil
+}
+
+func (tx *recordingTx) Commit(ctx context.Context) error {
+	return tx.close("commit", "release savepoint")
+}
+
+func (tx *recordingTx) Rollback(ctx context.Context) error {
+	return tx.close("rollback", "rollback to savepoint")
+}
+
+func (tx *recordingTx) close(outcome string, nestedOutcome string) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	if tx.nested {
+		outcome = nestedOutcome
+	}
+	tx.db.log = append(tx.db.log, outcome)
+	return nil
+}
+
+func TestPoolTxManager_Savepoints(t *testing.T) {
+	// Create dependencies
+	db := &recordingDB{}
+	manager := database.NewTxManager(db)
+	failure := errors.New("failed")
+
+	// A failed nested unit of work only rolls back its savepoint
+	err := manager.WithinTx(context.Background(), func(ctx context.Context) error {
+		outer, _ := database.TxFromContext(ctx)
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			inner, ok := database.TxFromContext(ctx)
+			require.True(t, ok)
+			require.NotSame(t, outer, inner)
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"begin", "savepoint", "rollback to savepoint", "commit"}, db.log)
+}
+
+func TestPoolTxManager_Retries(t *testing.T) {
+	conflict := &pgconn.PgError{Code: "40001"}
+
+	// Serialization failures are retried until the unit of work succeeds
+	db := &recordingDB{}
+	var attempts int
+	err := database.NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return conflict
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, []string{"begin", "rollback", "begin", "rollback", "begin", "commit"}, db.log)
+
+	// Retries stop after the configured number
+	attempts = 0
+	err = database.NewTxManager(&recordingDB{}, database.WithMaxRetries(2)).WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	require.True(t, database.IsSerializationFailure(err))
+	require.Equal(t, 3, attempts)
+
+	// Other errors are returned at once
+	attempts = 0
+	failure := errors.New("failed")
+	err = database.NewTxManager(&recordingDB{}).WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, attempts)
+
+	// Nested units of work are not retried on their own
+	attempts = 0
+	err = database.NewTxManager(&recordingDB{}, database.WithMaxRetries(0)).WithinTx(context.Background(), func(ctx context.Context) error {
+		return database.NewTxManager(&recordingDB{}).WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return conflict
+		})
+	})
+	require.ErrorIs(t, err, conflict)
+	require.Equal(t, 1, attempts)
+}
+
+func TestPoolTxManager_CancelledDuringBackoff(t *testing.T) {
+	// Create dependencies
+	ctx, cancel := context.WithCancel(context.Background())
+	conflict := &pgconn.PgError{Code: "40001"}
+
+	// Cancelling the context while backing off returns both errors without retrying
+	var attempts int
+	err := database.NewTxManager(&recordingDB{}).WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return conflict
+	})
+	require.ErrorIs(t, err, conflict)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, attempts)
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	// Conflicts between concurrent transactions are retried, also when wrapped
+	require.True(t, database.IsSerializationFailure(&pgconn.PgError{Code: "40001"}))
+	require.True(t, database.IsSerializationFailure(fmt.Errorf("failed to update task: %w", &pgconn.PgError{Code: "40P01"})))
+
+	// Other errors are final
+	require.False(t, database.IsSerializationFailure(&pgconn.PgError{Code: "23505"}))
+	require.False(t, database.IsSerializationFailure(errors.New("connection refused")))
+	require.False(t, database.IsSerializationFailure(nil))
+}
+
+func TestAfterCommit(t *testing.T) {
+	// Outside of a unit of work there is nothing to wait for
+	var ran bool
+	database.AfterCommit(context.Background(), func() { ran = true })
+	require.True(t, ran)
+
+	// Within a unit of work functions run once the transaction committed, also from savepoints
+	db := &recordingDB{}
+	manager := database.NewTxManager(db)
+	err := manager.WithinTx(context.Background(), func(ctx context.Context) error {
+		database.AfterCommit(ctx, func() { db.log = append(db.log, "first") })
+		return manager.WithinTx(ctx, func(ctx context.Context) error {
+			database.AfterCommit(ctx, func() { db.log = append(db.log, "second") })
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"begin", "savepoint", "release savepoint", "commit", "first", "second"}, db.log)
+
+	// Rolled back transactions drop their functions
+	db = &recordingDB{}
+	failure := errors.New("failed")
+	err = database.NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		database.AfterCommit(ctx, func() { db.log = append(db.log, "dropped") })
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, []string{"begin", "rollback"}, db.log)
+
+	// Only the functions of the attempt that committed run
+	db = &recordingDB{}
+	var attempts int
+	err = database.NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		database.AfterCommit(ctx, func() { db.log = append(db.log, fmt.Sprintf("attempt %d", attempts)) })
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"begin", "rollback", "begin", "commit", "attempt 2"}, db.log)
+}
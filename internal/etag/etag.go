@@ -0,0 +1,43 @@
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Header carries the version of the resource in a response
+	Header = "ETag"
+	// IfMatchHeader carries the version a write expects the resource to have
+	IfMatchHeader = "If-Match"
+)
+
+// ErrInvalid is returned for If-Match headers that do not hold a version this package issued
+var ErrInvalid = errors.New("invalid entity tag")
+
+// Format returns the entity tag of a version
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ParseIfMatch returns the version expected by an If-Match header, or 0 when any version
+// matches because the header is absent or "*". Weak tags are accepted since versions change
+// with every write.
+func ParseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, fmt.Errorf("%w: %s", ErrInvalid, header)
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalid, header)
+	}
+	return version, nil
+}
@@ -0,0 +1,33 @@
+package etag_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/etag"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	// Tags issued by Format round-trip
+	version, err := etag.ParseIfMatch(etag.Format(3))
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	// Weak tags are accepted
+	version, err = etag.ParseIfMatch(`W/"4"`)
+	require.NoError(t, err)
+	require.Equal(t, 4, version)
+
+	// Absent or wildcard headers match any version
+	for _, header := range []string{"", "*"} {
+		version, err = etag.ParseIfMatch(header)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+	}
+
+	// Other tags are rejected
+	for _, header := range []string{"3", `"abc"`, `"0"`, `"1", "2"`} {
+		_, err = etag.ParseIfMatch(header)
+		require.ErrorIs(t, err, etag.ErrInvalid)
+	}
+}
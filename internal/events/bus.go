@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event is a domain event read from the outbox
+type Event struct {
+	ID        int64
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	// Attempt counts delivery attempts, starting at 1
+	Attempt int
+}
+
+// Handler reacts to an event. Events are delivered at least once, so handlers must tolerate duplicates.
+type Handler func(ctx context.Context, event Event) error
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus routes events to the handlers subscribed to their type
+type Bus struct {
+	mutex         sync.RWMutex
+	subscriptions map[string][]subscription
+}
+
+func NewBus() *Bus {
+	bus := Bus{
+		subscriptions: map[string][]subscription{},
+	}
+
+	return &bus
+}
+
+// Subscribe registers a handler for events of the type. The name identifies the subscriber in
+// the outbox, so it must be unique per type and stable across restarts.
+func (b *Bus) Subscribe(eventType string, name string, handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{name: name, handler: handler})
+}
+
+// Deliver passes the event to every subscriber except those that already received it.
+// It returns the subscribers that have received the event so far, and the errors of the others.
+func (b *Bus) Deliver(ctx context.Context, event Event, deliveredTo []string) ([]string, error) {
+	b.mutex.RLock()
+	subscriptions := b.subscriptions[event.Type]
+	b.mutex.RUnlock()
+
+	delivered := slices.Clone(deliveredTo)
+	var errs []error
+	for _, subscription := range subscriptions {
+		if slices.Contains(delivered, subscription.name) {
+			continue
+		}
+		if err := call(ctx, subscription.handler, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subscription.name, err))
+			continue
+		}
+		delivered = append(delivered, subscription.name)
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// call turns panics of a handler into errors, so one subscriber cannot stop the dispatcher
+func call(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return handler(ctx, event)
+}
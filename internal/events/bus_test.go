@@ -0,0 +1,51 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/events"
+)
+
+func TestBus_Deliver(t *testing.T) {
+	// Create dependencies
+	bus := events.NewBus()
+	var received []string
+	bus.Subscribe("TaskCreated", "audit", func(ctx context.Context, event events.Event) error {
+		received = append(received, "audit")
+		return nil
+	})
+	failing := true
+	bus.Subscribe("TaskCreated", "webhooks", func(ctx context.Context, event events.Event) error {
+		received = append(received, "webhooks")
+		if failing {
+			return errors.New("endpoint unavailable")
+		}
+		return nil
+	})
+	bus.Subscribe("TaskCreated", "broken", func(ctx context.Context, event events.Event) error {
+		panic("nil map")
+	})
+	bus.Subscribe("TaskStatusChanged", "other", func(ctx context.Context, event events.Event) error {
+		received = append(received, "other")
+		return nil
+	})
+	event := events.Event{ID: 1, Type: "TaskCreated", Payload: []byte(`{}`)}
+
+	// Failing and panicking subscribers are reported, the others receive the event
+	delivered, err := bus.Deliver(t.Context(), event, nil)
+	require.ErrorContains(t, err, "webhooks: endpoint unavailable")
+	require.ErrorContains(t, err, "broken: panic: nil map")
+	require.Equal(t, []string{"audit"}, delivered)
+	require.Equal(t, []string{"audit", "webhooks"}, received)
+
+	// Retries skip the subscribers that already received the event
+	failing = false
+	received = nil
+	delivered, err = bus.Deliver(t.Context(), event, delivered)
+	require.Error(t, err)
+	require.Equal(t, []string{"audit", "webhooks"}, delivered)
+	require.Equal(t, []string{"webhooks"}, received)
+}
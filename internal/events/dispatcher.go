@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+)
+
+// Dispatcher delivers events from the outbox to the subscribers of the bus. Instances share the
+// outbox; each event is locked by the instance delivering it.
+type Dispatcher struct {
+	pool   *pgxpool.Pool
+	bus    *Bus
+	config config.OutboxConfig
+	logger *slog.Logger
+}
+
+func NewDispatcher(pool *pgxpool.Pool, bus *Bus, cfg config.OutboxConfig, logger *slog.Logger) *Dispatcher {
+	dispatcher := Dispatcher{
+		pool:   pool,
+		bus:    bus,
+		config: cfg,
+		logger: logger,
+	}
+
+	return &dispatcher
+}
+
+// Run delivers due events until the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		dispatched, err := d.DispatchNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error("Failed to dispatch outbox event", slog.Any("error", err))
+		}
+
+		// Drain the outbox without waiting, then poll
+		if dispatched && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// DispatchNext delivers the oldest due event and reports whether there was one
+func (d *Dispatcher) DispatchNext(ctx context.Context) (bool, error) {
+	dispatched := false
+	err := pgx.BeginFunc(ctx, d.pool, func(tx pgx.Tx) error {
+		query := `
+			SELECT id, type, payload, created_at, attempts, delivered_to FROM outbox_events
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED`
+		var event Event
+		var deliveredTo []string
+		err := tx.QueryRow(ctx, query).Scan(&event.ID, &event.Type, &event.Payload, &event.CreatedAt, &event.Attempt, &deliveredTo)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get outbox event: %w", err)
+		}
+		dispatched = true
+		event.Attempt++
+
+		logger := d.logger.With(slog.Int64("event_id", event.ID), slog.String("event_type", event.Type), slog.Int("attempt", event.Attempt))
+		deliveredTo, deliverErr := d.bus.Deliver(logging.WithLogger(ctx, logger), event, deliveredTo)
+		if deliverErr == nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE outbox_events SET status = 'delivered', attempts = $2, delivered_to = $3, last_error = NULL, delivered_at = NOW()
+				WHERE id = $1`, event.ID, event.Attempt, deliveredTo)
+			if err != nil {
+				return fmt.Errorf("failed to mark outbox event delivered: %w", err)
+			}
+			return nil
+		}
+
+		// Retry later, or give up and leave the event for inspection
+		status := "pending"
+		if event.Attempt >= d.config.MaxAttempts {
+			status = "dead"
+			logger.Error("Outbox event dead-lettered", slog.Any("error", deliverErr))
+		} else {
+			logger.Warn("Outbox event delivery failed, retrying", slog.Any("error", deliverErr))
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE outbox_events SET status = $2, attempts = $3, delivered_to = $4, last_error = $5, next_attempt_at = $6
+			WHERE id = $1`, event.ID, status, event.Attempt, deliveredTo, deliverErr.Error(), time.Now().Add(Backoff(d.config.RetryBackoff, d.config.MaxRetryBackoff, event.Attempt)))
+		if err != nil {
+			return fmt.Errorf("failed to reschedule outbox event: %w", err)
+		}
+		return nil
+	})
+	return dispatched, err
+}
+
+// Backoff returns the delay after a failed attempt, starting at 1. It doubles the base delay
+// with every further attempt, up to the maximum.
+func Backoff(base time.Duration, maximum time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maximum; i++ {
+		delay *= 2
+	}
+	return min(delay, maximum)
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+// ErrNoTransaction is returned when an event is published outside of a unit of work
+var ErrNoTransaction = errors.New("events must be published within a transaction")
+
+// Publisher records events to be delivered once the change that raised them is committed
+type Publisher interface {
+	// Publish writes the event to the outbox in the transaction of the context, see database.TxManager
+	Publish(ctx context.Context, eventType string, payload any) error
+}
+
+// OutboxPublisher writes events to the outbox_events table
+type OutboxPublisher struct{}
+
+func NewOutboxPublisher() *OutboxPublisher {
+	return &OutboxPublisher{}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, eventType string, payload any) error {
+	// Without the transaction of the change the event could be delivered for a change that was rolled back
+	tx, ok := database.TxFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("failed to publish %s: %w", eventType, ErrNoTransaction)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", eventType, err)
+	}
+
+	query := `INSERT INTO outbox_events (type, payload) VALUES ($1, $2)`
+	if _, err := tx.Exec(ctx, requestid.SQLComment(ctx, query), eventType, string(data)); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sumup/dependency-injection-go/internal/migrations"
+)
+
+// Pinger is satisfied by *pgxpool.Pool and *pgx.Conn
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingCheck reports whether the database answers a ping
+func PingCheck(name string, pinger Pinger) Check {
+	return Check{
+		Name: name,
+		Run:  pinger.Ping,
+	}
+}
+
+// MigrationCheck reports whether the database schema has every migration this build expects
+func MigrationCheck(db migrations.Querier) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			return migrations.CheckVersion(ctx, db)
+		},
+	}
+}
+
+// SingleWorkspaceCheck reports whether the database holds only the default workspace.
+// Servers that cannot tell the callers of different workspaces apart must not serve more.
+func SingleWorkspaceCheck(db migrations.Querier) Check {
+	return Check{
+		Name: "workspaces",
+		Run: func(ctx context.Context) error {
+			var workspaces int
+			if err := db.QueryRow(ctx, `SELECT count(*) FROM workspaces`).Scan(&workspaces); err != nil {
+				return fmt.Errorf("failed to count workspaces: %w", err)
+			}
+			if workspaces > 1 {
+				return fmt.Errorf("%d workspaces exist, enable authentication to serve more than one", workspaces)
+			}
+			return nil
+		},
+	}
+}
+
+// ReplicaLagCheck reports whether the replica at dsn replays changes within maxLag.
+// It opens a short-lived connection per run so it holds no resources between probes.
+func ReplicaLagCheck(dsn string, maxLag time.Duration) Check {
+	return Check{
+		Name: "replica",
+		Run: func(ctx context.Context) error {
+			conn, err := pgx.Connect(ctx, dsn)
+			if err != nil {
+				return fmt.Errorf("failed to connect to replica: %w", err)
+			}
+			defer conn.Close(context.Background())
+
+			var inRecovery bool
+			var lagSeconds float64
+			err = conn.QueryRow(ctx, `
+				SELECT pg_is_in_recovery(),
+					COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8`,
+			).Scan(&inRecovery, &lagSeconds)
+			if err != nil {
+				return fmt.Errorf("failed to read replica lag: %w", err)
+			}
+
+			if !inRecovery {
+				return fmt.Errorf("database is not a replica")
+			}
+			if lag := time.Duration(lagSeconds * float64(time.Second)); lag > maxLag {
+				return fmt.Errorf("replica is %s behind, more than the allowed %s", lag.Round(time.Millisecond), maxLag)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/health"
+)
+
+func TestChecker_ReadyHandler(t *testing.T) {
+	// Create a checker whose checks all pass
+	checker := health.NewChecker(time.Second, health.Check{
+		Name: "database",
+		Run:  func(ctx context.Context) error { return nil },
+	})
+
+	recorder := httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var report health.Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Equal(t, health.StatusUp, report.Status)
+	require.Equal(t, health.StatusUp, report.Checks["database"].Status)
+
+	// Register a failing check and a check that hangs past its timeout
+	checker.Register(health.Check{
+		Name: "migrations",
+		Run:  func(ctx context.Context) error { return errors.New("schema is at version 0, expected 1") },
+	})
+	checker.Register(health.Check{
+		Name:    "replica",
+		Timeout: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+
+	start := time.Now()
+	recorder = httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// The report is down, lists every check and does not wait for the hanging one
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Equal(t, health.StatusDown, report.Status)
+	require.Equal(t, health.StatusUp, report.Checks["database"].Status)
+	require.Equal(t, health.StatusDown, report.Checks["migrations"].Status)
+	require.Equal(t, "schema is at version 0, expected 1", report.Checks["migrations"].Error)
+	require.Equal(t, health.StatusDown, report.Checks["replica"].Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["replica"].Error)
+}
+
+func TestLiveHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	health.LiveHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status":"up"}`, recorder.Body.String())
+}
@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// Server is an http.Server with hardened timeouts that drains in-flight requests on shutdown
+type Server struct {
+	server   *http.Server
+	config   config.HTTPConfig
+	inFlight atomic.Int64
+}
+
+// New creates a server for the handler using the configured timeouts and limits
+func New(cfg config.HTTPConfig, handler http.Handler) *Server {
+	s := &Server{
+		config: cfg,
+	}
+	s.server = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.track(handler),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+	return s
+}
+
+// track counts the requests currently being handled
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns the number of requests currently being handled
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// Run serves requests until ctx is cancelled and then shuts the server down.
+// In-flight requests get the configured shutdown timeout to finish; the ones
+// still running after that are cut off and reported. Run returns once the
+// server has fully stopped, so callers can release resources afterwards.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve is like Run but accepts connections on an existing listener
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, draining %d in-flight requests (timeout %s)", s.InFlight(), s.config.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	err := s.server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		cutOff := s.InFlight()
+		if closeErr := s.server.Close(); closeErr != nil {
+			log.Printf("Error closing server: %v", closeErr)
+		}
+		log.Printf("Shutdown timeout exceeded, %d in-flight requests were cut off", cutOff)
+	} else if err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	} else {
+		log.Printf("Server stopped, all in-flight requests completed")
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package httpserver_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/httpserver"
+)
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	// Create a server whose handler takes a while to answer
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := config.Default().HTTP
+	cfg.ShutdownTimeout = 2 * time.Second
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httpserver.New(cfg, handler)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Serve(ctx, listener)
+	}()
+
+	// Shut down while the request is being handled
+	response := make(chan *http.Response, 1)
+	requestErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		response <- resp
+		requestErr <- err
+	}()
+	<-started
+	require.Equal(t, int64(1), server.InFlight())
+	cancel()
+
+	// The request still completes and the server stops cleanly
+	resp := <-response
+	require.NoError(t, <-requestErr)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	require.NoError(t, <-stopped)
+	require.Equal(t, int64(0), server.InFlight())
+}
+
+func TestServer_CutsOffRequestsAfterTimeout(t *testing.T) {
+	// Create a server whose handler never finishes on its own
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	cfg := config.Default().HTTP
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httpserver.New(cfg, handler)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Serve(ctx, listener)
+	}()
+
+	requestErr := make(chan error, 1)
+	go func() {
+		_, err := http.Get("http://" + listener.Addr().String())
+		requestErr <- err
+	}()
+	<-started
+	cancel()
+
+	// The server gives up after the timeout and the client sees the connection closed
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after the shutdown timeout")
+	}
+	require.Error(t, <-requestErr)
+}
@@ -0,0 +1,183 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/etag"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+)
+
+const (
+	// Header carries the key chosen by the client for a request it may retry
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks responses replayed from an earlier request
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// maxKeyLength bounds the keys clients may send
+	maxKeyLength = 255
+	// pollInterval is how often a duplicate checks whether the first request completed
+	pollInterval = 100 * time.Millisecond
+)
+
+// replayedHeaders are stored with the response, so that a retry can use it like the original
+var replayedHeaders = []string{etag.Header}
+
+// GinMiddleware replays the stored response when a POST or PATCH request is repeated with the same
+// Idempotency-Key. Keys belong to the owner of the request, usually the authenticated caller, so
+// it must run after the authentication middleware.
+func GinMiddleware(cfg config.IdempotencyConfig, store Store, owner func(*http.Request) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if !cfg.Enabled || key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if len(key) > maxKeyLength {
+			abort(c, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abort(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := Fingerprint(c.Request, body)
+
+		// Wait for an earlier request with the key to complete, then replay its response
+		requester := owner(c.Request)
+		deadline := time.Now().Add(cfg.WaitTimeout)
+		var lockedAt time.Time
+		for {
+			record, claimed, err := store.Claim(ctx, requester, key, fingerprint)
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to claim idempotency key", slog.Any("error", err))
+				abort(c, http.StatusInternalServerError, "failed to check Idempotency-Key")
+				return
+			}
+			if claimed {
+				lockedAt = record.LockedAt
+				break
+			}
+
+			if record.Fingerprint != fingerprint {
+				abort(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+				return
+			}
+			if record.Response != nil {
+				replay(c, *record.Response)
+				return
+			}
+			if time.Now().After(deadline) {
+				abort(c, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				c.Abort()
+				return
+			case <-time.After(pollInterval):
+			}
+		}
+
+		// The key is kept even if the client goes away, so its retry gets the response
+		storeCtx := context.WithoutCancel(ctx)
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Release the key when the handler panics so that the request can be retried
+		defer func() {
+			if p := recover(); p != nil {
+				release(storeCtx, store, requester, key, lockedAt)
+				panic(p)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not final; retrying may succeed
+		if recorder.Status() >= http.StatusInternalServerError {
+			release(storeCtx, store, requester, key, lockedAt)
+			return
+		}
+		response := Response{
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Header:      http.Header{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			for _, value := range recorder.Header().Values(name) {
+				response.Header.Add(name, value)
+			}
+		}
+		if err := store.Complete(storeCtx, requester, key, lockedAt, response); err != nil {
+			logging.FromContext(ctx).Error("Failed to store idempotent response", slog.Any("error", err))
+			release(storeCtx, store, requester, key, lockedAt)
+		}
+	}
+}
+
+// Fingerprint identifies the request a key was used for by its method, target and body
+func Fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(c *gin.Context, response Response) {
+	if response.ContentType != "" {
+		c.Header("Content-Type", response.ContentType)
+	}
+	for name, values := range response.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(ReplayedHeader, "true")
+	c.Status(response.StatusCode)
+	_, _ = c.Writer.Write(response.Body)
+	c.Abort()
+}
+
+func release(ctx context.Context, store Store, owner string, key string, lockedAt time.Time) {
+	if err := store.Release(ctx, owner, key, lockedAt); err != nil {
+		logging.FromContext(ctx).Error("Failed to release idempotency key", slog.Any("error", err))
+	}
+}
+
+func abort(c *gin.Context, status int, detail string) {
+	problem.Write(c.Writer, c.Request, problem.New(status, detail))
+	c.Abort()
+}
+
+// responseRecorder keeps a copy of the response body written by the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,214 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/idempotency"
+)
+
+// memoryStore keeps keys in memory for the tests
+type memoryStore struct {
+	mutex   sync.Mutex
+	records map[string]idempotency.Record
+	claims  int
+}
+
+func (s *memoryStore) Claim(ctx context.Context, owner string, key string, fingerprint string) (idempotency.Record, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, ok := s.records[owner+"|"+key]; ok {
+		return record, false, nil
+	}
+	return s.takeOver(owner, key, fingerprint), true, nil
+}
+
+// takeOver claims the key whether or not it is held, as Claim does once the lock timeout has passed
+func (s *memoryStore) takeOver(owner string, key string, fingerprint string) idempotency.Record {
+	s.claims++
+	record := idempotency.Record{Fingerprint: fingerprint, LockedAt: time.Unix(int64(s.claims), 0)}
+	s.records[owner+"|"+key] = record
+	return record
+}
+
+func (s *memoryStore) Complete(ctx context.Context, owner string, key string, lockedAt time.Time, response idempotency.Response) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, ok := s.records[owner+"|"+key]
+	if !ok || !record.LockedAt.Equal(lockedAt) {
+		return nil
+	}
+	record.Response = &response
+	s.records[owner+"|"+key] = record
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, owner string, key string, lockedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, ok := s.records[owner+"|"+key]; ok && record.LockedAt.Equal(lockedAt) && record.Response == nil {
+		delete(s.records, owner+"|"+key)
+	}
+	return nil
+}
+
+func TestGinMiddleware(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	cfg := config.IdempotencyConfig{Enabled: true, TTL: time.Hour, WaitTimeout: 5 * time.Second, LockTimeout: time.Minute}
+	store := &memoryStore{records: map[string]idempotency.Record{}}
+
+	var calls int
+	var mutex sync.Mutex
+	proceed := make(chan struct{})
+	router := gin.New()
+	router.Use(idempotency.GinMiddleware(cfg, store, func(r *http.Request) string { return r.Header.Get("X-Caller") }))
+	router.POST("/tasks", func(c *gin.Context) {
+		mutex.Lock()
+		calls++
+		id := calls
+		mutex.Unlock()
+
+		if c.Query("slow") != "" {
+			<-proceed
+		}
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Header("ETag", `"1"`)
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+
+	serve := func(target, key, caller, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(idempotency.Header, key)
+		}
+		request.Header.Set("X-Caller", caller)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Repeated requests replay the first response
+	first := serve("/tasks", "a", "alice", `{"title":"A"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(idempotency.ReplayedHeader))
+
+	repeat := serve("/tasks", "a", "alice", `{"title":"A"}`)
+	require.Equal(t, http.StatusCreated, repeat.Code)
+	require.Equal(t, "true", repeat.Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, first.Header().Get("Content-Type"), repeat.Header().Get("Content-Type"))
+	require.Equal(t, `"1"`, repeat.Header().Get("ETag"))
+	require.JSONEq(t, `{"id":1}`, repeat.Body.String())
+	require.Equal(t, 1, calls)
+
+	// A key reused for a different request is rejected
+	require.Equal(t, http.StatusUnprocessableEntity, serve("/tasks", "a", "alice", `{"title":"B"}`).Code)
+
+	// Keys of different callers and requests without key are independent
+	require.JSONEq(t, `{"id":2}`, serve("/tasks", "a", "bob", `{"title":"A"}`).Body.String())
+	require.JSONEq(t, `{"id":3}`, serve("/tasks", "", "alice", `{"title":"A"}`).Body.String())
+
+	// Server errors release the key so that the request can be retried
+	require.Equal(t, http.StatusInternalServerError, serve("/tasks?fail=1", "b", "alice", "").Code)
+	require.Equal(t, http.StatusInternalServerError, serve("/tasks?fail=1", "b", "alice", "").Code)
+	require.Equal(t, 5, calls)
+
+	// Concurrent duplicates wait for the first request to complete
+	responses := make(chan *httptest.ResponseRecorder, 2)
+	go func() { responses <- serve("/tasks?slow=1", "c", "alice", "") }()
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return calls == 6
+	}, time.Second, 10*time.Millisecond)
+	go func() { responses <- serve("/tasks?slow=1", "c", "alice", "") }()
+	time.Sleep(150 * time.Millisecond)
+	close(proceed)
+
+	for range 2 {
+		response := <-responses
+		require.Equal(t, http.StatusCreated, response.Code)
+		require.JSONEq(t, `{"id":6}`, response.Body.String())
+	}
+	require.Equal(t, 6, calls)
+}
+
+func TestGinMiddleware_WaitTimeout(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	cfg := config.IdempotencyConfig{Enabled: true, TTL: time.Hour, WaitTimeout: 50 * time.Millisecond, LockTimeout: time.Minute}
+	store := &memoryStore{records: map[string]idempotency.Record{}}
+	router := gin.New()
+	router.Use(idempotency.GinMiddleware(cfg, store, func(r *http.Request) string { return "alice" }))
+	router.POST("/tasks", func(c *gin.Context) {})
+
+	// A request holding the key
+	request := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	_, _, err := store.Claim(t.Context(), "alice", "a", idempotency.Fingerprint(request, nil))
+	require.NoError(t, err)
+
+	// Duplicates give up once the wait timeout has passed
+	request.Header.Set(idempotency.Header, "a")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestGinMiddleware_ClaimTakenOver(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	cfg := config.IdempotencyConfig{Enabled: true, TTL: time.Hour, WaitTimeout: time.Second, LockTimeout: time.Minute}
+	store := &memoryStore{records: map[string]idempotency.Record{}}
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	router := gin.New()
+	router.Use(idempotency.GinMiddleware(cfg, store, func(r *http.Request) string { return "alice" }))
+	router.POST("/tasks", func(c *gin.Context) {
+		started <- struct{}{}
+		<-proceed
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	for _, target := range []string{"/tasks", "/tasks?fail=1"} {
+		request := httptest.NewRequest(http.MethodPost, target, nil)
+		request.Header.Set(idempotency.Header, target)
+		done := make(chan struct{})
+		go func() {
+			router.ServeHTTP(httptest.NewRecorder(), request)
+			close(done)
+		}()
+
+		// Another request takes the key over while the slow one is still running
+		<-started
+		store.mutex.Lock()
+		claim := store.takeOver("alice", target, idempotency.Fingerprint(request, nil))
+		store.mutex.Unlock()
+		proceed <- struct{}{}
+		<-done
+
+		// The slow request neither completes nor releases the claim it no longer holds
+		store.mutex.Lock()
+		record, ok := store.records["alice|"+target]
+		store.mutex.Unlock()
+		require.True(t, ok)
+		require.Equal(t, claim, record)
+	}
+}
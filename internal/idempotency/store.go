@@ -0,0 +1,162 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// Record is what is known about a claim of a key
+type Record struct {
+	Fingerprint string
+	// LockedAt identifies the claim. Once the lock timeout has passed another request can take
+	// the key over, and the request holding the earlier claim can no longer complete or release it.
+	LockedAt time.Time
+	// Response is nil while the earlier request is in progress
+	Response *Response
+}
+
+// Response is a stored response, replayed for repeated requests
+type Response struct {
+	StatusCode  int
+	ContentType string
+	// Header holds the headers replayed with the body, such as ETag
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps idempotency keys and their responses
+type Store interface {
+	// Claim reserves the key for a request with the fingerprint and returns the record of the claim.
+	// When an earlier request holds the key it returns false with the record of that request.
+	Claim(ctx context.Context, owner string, key string, fingerprint string) (Record, bool, error)
+	// Complete stores the response of the request, unless its claim was taken over
+	Complete(ctx context.Context, owner string, key string, lockedAt time.Time, response Response) error
+	// Release forgets the key so the request can be retried, unless its claim was taken over
+	Release(ctx context.Context, owner string, key string, lockedAt time.Time) error
+}
+
+// sweepInterval is how often expired keys are deleted
+const sweepInterval = time.Minute
+
+// PostgresStore keeps keys in the idempotency_keys table, shared by every instance
+type PostgresStore struct {
+	pool   *pgxpool.Pool
+	config config.IdempotencyConfig
+
+	sweepMutex sync.Mutex
+	lastSweep  time.Time
+}
+
+func NewPostgresStore(pool *pgxpool.Pool, cfg config.IdempotencyConfig) *PostgresStore {
+	store := PostgresStore{
+		pool:   pool,
+		config: cfg,
+	}
+
+	return &store
+}
+
+func (s *PostgresStore) Claim(ctx context.Context, owner string, key string, fingerprint string) (Record, bool, error) {
+	now := time.Now()
+	s.sweep(ctx, now)
+
+	for {
+		// Take over keys that expired or whose request was abandoned before it completed
+		query := `
+			INSERT INTO idempotency_keys (owner, key, fingerprint, locked_at, expires_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (owner, key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, locked_at = EXCLUDED.locked_at, expires_at = EXCLUDED.expires_at,
+				status_code = NULL, content_type = NULL, headers = NULL, body = NULL
+			WHERE idempotency_keys.expires_at <= $4
+				OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_at <= $6)
+			RETURNING locked_at`
+		claim := Record{Fingerprint: fingerprint}
+		err := s.pool.QueryRow(ctx, query, owner, key, fingerprint, now, now.Add(s.config.TTL), now.Add(-s.config.LockTimeout)).Scan(&claim.LockedAt)
+		if err == nil {
+			return claim, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+
+		var record Record
+		var statusCode *int
+		var contentType *string
+		var headers []byte
+		var body []byte
+		err = s.pool.QueryRow(ctx,
+			`SELECT fingerprint, locked_at, status_code, content_type, headers, body FROM idempotency_keys WHERE owner = $1 AND key = $2`,
+			owner, key).Scan(&record.Fingerprint, &record.LockedAt, &statusCode, &contentType, &headers, &body)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The earlier request released the key in the meantime
+			continue
+		}
+		if err != nil {
+			return Record{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		if statusCode != nil {
+			record.Response = &Response{StatusCode: *statusCode, Body: body}
+			if contentType != nil {
+				record.Response.ContentType = *contentType
+			}
+			if headers != nil {
+				if err := json.Unmarshal(headers, &record.Response.Header); err != nil {
+					return Record{}, false, fmt.Errorf("failed to decode idempotent response headers: %w", err)
+				}
+			}
+		}
+		return record, false, nil
+	}
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, owner string, key string, lockedAt time.Time, response Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response headers: %w", err)
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		UPDATE idempotency_keys SET status_code = $4, content_type = $5, headers = $6::JSONB, body = $7
+		WHERE owner = $1 AND key = $2 AND locked_at = $3`,
+		owner, key, lockedAt, response.StatusCode, response.ContentType, string(headers), response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, owner string, key string, lockedAt time.Time) error {
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE owner = $1 AND key = $2 AND locked_at = $3 AND status_code IS NULL`,
+		owner, key, lockedAt)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// sweep deletes expired keys, at most once per interval and never concurrently
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) {
+	if !s.sweepMutex.TryLock() {
+		return
+	}
+	defer s.sweepMutex.Unlock()
+
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	// A failed sweep is retried at the next interval
+	_, _ = s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+}
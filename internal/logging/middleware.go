@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GinMiddleware attaches a request-scoped logger to the request context and logs every completed request.
+// Install it after requestid.GinMiddleware so log lines carry the request ID.
+func GinMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		requestLogger := logger.With(
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+		)
+		if id := requestid.FromContext(c.Request.Context()); id != "" {
+			requestLogger = requestLogger.With(slog.String("request_id", id))
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+			requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(WithLogger(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		// Handlers may have enriched the logger, for example with the user
+		requestLogger = FromContext(c.Request.Context())
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Error()))
+		}
+		requestLogger.LogAttrs(c.Request.Context(), level, "Request completed", attrs...)
+	}
+}
+
+// GinRecovery turns panics into 500 responses and logs them with the request-scoped logger
+func GinRecovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		FromContext(c.Request.Context()).Error("Recovered from panic", slog.Any("panic", recovered))
+		problem.Write(c.Writer, c.Request, problem.New(http.StatusInternalServerError, "The server encountered an unexpected error"))
+		c.Abort()
+	})
+}
+
+// Middleware is GinMiddleware for net/http handlers. The route function must map a request
+// to its route pattern, such as "/tasks/:id", or return an empty string for unmatched requests.
+// Install it inside requestid.Middleware so log lines carry the request ID.
+func Middleware(logger *slog.Logger, route func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		pattern := route(r)
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+
+		requestLogger := logger.With(
+			slog.String("method", r.Method),
+			slog.String("route", pattern),
+		)
+		if id := requestid.FromContext(r.Context()); id != "" {
+			requestLogger = requestLogger.With(slog.String("request_id", id))
+		}
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+			requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(WithLogger(r.Context(), requestLogger)))
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if recorder.status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		requestLogger.LogAttrs(r.Context(), level, "Request completed",
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("size", recorder.size),
+		)
+	})
+}
+
+// responseRecorder captures the status code and body size written by a handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
@@ -0,0 +1,114 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+func TestGinMiddleware_RequestScopedLogger(t *testing.T) {
+	// Create dependencies
+	var output bytes.Buffer
+	logger := logging.New(config.LogConfig{Level: "info", Format: "json"}, &output)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.GinMiddleware(), logging.GinMiddleware(logger), logging.GinRecovery())
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("Handling task")
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	// Send requests
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks/42", nil))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Contains(t, recorder.Body.String(), recorder.Header().Get(requestid.Header))
+
+	// Every line of a request carries the same request ID and route
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 4)
+
+	require.Equal(t, "Handling task", records[0]["msg"])
+	require.Equal(t, "/tasks/:id", records[0]["route"])
+	require.NotEmpty(t, records[0]["request_id"])
+	require.Equal(t, "Request completed", records[1]["msg"])
+	require.Equal(t, records[0]["request_id"], records[1]["request_id"])
+	require.EqualValues(t, http.StatusNoContent, records[1]["status"])
+
+	require.Equal(t, "Recovered from panic", records[2]["msg"])
+	require.Equal(t, "ERROR", records[3]["level"])
+	require.NotEqual(t, records[0]["request_id"], records[2]["request_id"])
+}
+
+func TestMiddleware_RequestScopedLogger(t *testing.T) {
+	// Create dependencies
+	var output bytes.Buffer
+	logger := logging.New(config.LogConfig{Level: "info", Format: "json"}, &output)
+
+	route := func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/tasks/") {
+			return "/tasks/:id"
+		}
+		return ""
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("Handling task")
+		w.WriteHeader(http.StatusConflict)
+	})
+	server := requestid.Middleware(logging.Middleware(logger, route, handler))
+
+	// Send requests
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/tasks/42", nil))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	// Every line of a request carries the same request ID and route
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 4)
+
+	require.Equal(t, "Handling task", records[0]["msg"])
+	require.Equal(t, "/tasks/:id", records[0]["route"])
+	require.Equal(t, recorder.Header().Get(requestid.Header), records[0]["request_id"])
+	require.Equal(t, "Request completed", records[1]["msg"])
+	require.Equal(t, records[0]["request_id"], records[1]["request_id"])
+	require.Equal(t, "WARN", records[1]["level"])
+	require.EqualValues(t, http.StatusConflict, records[1]["status"])
+
+	require.Equal(t, "unmatched", records[3]["route"])
+	require.NotEqual(t, records[0]["request_id"], records[3]["request_id"])
+}
+
+func TestNew_Level(t *testing.T) {
+	var output bytes.Buffer
+	logger := logging.New(config.LogConfig{Level: "warn", Format: "text"}, &output)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	require.NotContains(t, output.String(), "hidden")
+	require.Contains(t, output.String(), "msg=shown")
+}
@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := metrics.New()
+
+	// Wrap a handler that fails for one of its routes
+	handler := m.Middleware(func(r *http.Request) string {
+		if r.URL.Path == "/tasks" {
+			return "/tasks"
+		}
+		return ""
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/tasks", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/123", nil))
+
+	// Record repository calls as well
+	m.ObserveRepositoryCall("GetAllTasks", time.Now(), nil)
+	m.ObserveRepositoryCall("CreateTask", time.Now(), errors.New("boom"))
+	m.ObserveCacheLookup("GetAllTasks", true)
+	m.ObserveCacheLookup("GetAllTasks", false)
+
+	// Scrape the endpoint
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, body, `http_requests_total{method="GET",route="/tasks",status="200"} 1`)
+	require.Contains(t, body, `http_requests_total{method="POST",route="/tasks",status="500"} 1`)
+	require.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="200"} 1`)
+	require.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/tasks"} 1`)
+	require.Contains(t, body, `repository_call_duration_seconds_count{method="GetAllTasks"} 1`)
+	require.Contains(t, body, `repository_call_errors_total{method="CreateTask"} 1`)
+	require.NotContains(t, body, `repository_call_errors_total{method="GetAllTasks"}`)
+	require.Contains(t, body, `repository_cache_lookups_total{method="GetAllTasks",result="hit"} 1`)
+	require.Contains(t, body, `repository_cache_lookups_total{method="GetAllTasks",result="miss"} 1`)
+}
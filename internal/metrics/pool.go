@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports pgxpool.Stat as gauges and counters on every scrape
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns      *prometheus.Desc
+	idleConns          *prometheus.Desc
+	totalConns         *prometheus.Desc
+	maxConns           *prometheus.Desc
+	constructingConns  *prometheus.Desc
+	acquireCount       *prometheus.Desc
+	acquireWaitSeconds *prometheus.Desc
+	emptyAcquireCount  *prometheus.Desc
+	canceledAcquires   *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector reporting the statistics of the pool
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	return &poolCollector{
+		pool: pool,
+
+		acquiredConns:      prometheus.NewDesc("pgxpool_acquired_conns", "Number of connections currently acquired from the pool.", nil, nil),
+		idleConns:          prometheus.NewDesc("pgxpool_idle_conns", "Number of idle connections in the pool.", nil, nil),
+		totalConns:         prometheus.NewDesc("pgxpool_total_conns", "Total number of connections in the pool.", nil, nil),
+		maxConns:           prometheus.NewDesc("pgxpool_max_conns", "Maximum size of the pool.", nil, nil),
+		constructingConns:  prometheus.NewDesc("pgxpool_constructing_conns", "Number of connections being established.", nil, nil),
+		acquireCount:       prometheus.NewDesc("pgxpool_acquire_count_total", "Number of successful acquires from the pool.", nil, nil),
+		acquireWaitSeconds: prometheus.NewDesc("pgxpool_acquire_duration_seconds_total", "Total time spent acquiring connections from the pool.", nil, nil),
+		emptyAcquireCount:  prometheus.NewDesc("pgxpool_empty_acquire_count_total", "Number of acquires that had to wait because the pool was empty.", nil, nil),
+		canceledAcquires:   prometheus.NewDesc("pgxpool_canceled_acquire_count_total", "Number of acquires cancelled by their context.", nil, nil),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.constructingConns
+	ch <- c.acquireCount
+	ch <- c.acquireWaitSeconds
+	ch <- c.emptyAcquireCount
+	ch <- c.canceledAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWaitSeconds, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing tasks and users move to the default workspace, which always has id 1
+INSERT INTO workspaces (id, name) VALUES (1, 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), (SELECT max(id) FROM workspaces));
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id),
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+INSERT INTO workspace_members (workspace_id, user_id)
+SELECT 1, id FROM users
+ON CONFLICT DO NOTHING;
+
+-- Single-tenant servers that do not set a workspace keep writing to the default one
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS workspace_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_idx ON tasks (workspace_id);
+
+-- tasks_app is the role the multi-tenant server switches to for every transaction.
+-- Row-level security applies to it, while the table owner and superusers used by the
+-- single-tenant servers and manage bypass it.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'tasks_app') THEN
+        CREATE ROLE tasks_app NOLOGIN NOBYPASSRLS;
+    END IF;
+END
+$$;
+
+GRANT tasks_app TO CURRENT_USER;
+GRANT SELECT, INSERT, UPDATE, DELETE ON tasks TO tasks_app;
+GRANT USAGE ON SEQUENCE tasks_id_seq TO tasks_app;
+
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+
+-- Rows are only visible and writable within the workspace set for the transaction.
+-- When app.workspace_id is not set no row matches.
+DROP POLICY IF EXISTS tasks_workspace_isolation ON tasks;
+CREATE POLICY tasks_workspace_isolation ON tasks
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- prefix identifies the key in lookups and listings; only the hash of the whole key is stored
+    prefix TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id),
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
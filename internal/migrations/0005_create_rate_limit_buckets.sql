@@ -0,0 +1,9 @@
+-- Token buckets shared by every instance when rateLimit.store is postgres
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    -- NULL until the first token is taken from a new, full bucket
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_updated_at_idx ON rate_limit_buckets (updated_at);
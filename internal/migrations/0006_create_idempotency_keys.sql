@@ -0,0 +1,16 @@
+-- Responses to requests with an Idempotency-Key, replayed when the request is repeated
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    -- owner identifies the caller, so keys of different callers never collide
+    owner TEXT NOT NULL,
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    -- status_code is NULL while the first request is still in progress
+    status_code INTEGER,
+    content_type TEXT,
+    body BYTEA,
+    locked_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (owner, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+-- Every write bumps the version, so writers can detect that a task changed since they read it
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- Tasks can only have the statuses of the task state machine
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('pending', 'in_progress', 'blocked', 'completed', 'cancelled'));
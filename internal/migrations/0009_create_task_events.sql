@@ -0,0 +1,28 @@
+-- Every change to a task, written in the same transaction as the change itself
+CREATE TABLE IF NOT EXISTS task_events (
+    id BIGSERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id),
+    -- type is created or status_changed
+    type TEXT NOT NULL,
+    -- actor is the subject of the caller, NULL for anonymous callers
+    actor TEXT,
+    -- before and after hold the changed fields of the task
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
+CREATE INDEX IF NOT EXISTS task_events_workspace_id_idx ON task_events (workspace_id, id);
+
+-- Events are appended, never changed
+GRANT SELECT, INSERT ON task_events TO tasks_app;
+GRANT USAGE ON SEQUENCE task_events_id_seq TO tasks_app;
+
+ALTER TABLE task_events ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS task_events_workspace_isolation ON task_events;
+CREATE POLICY task_events_workspace_isolation ON task_events
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER);
@@ -0,0 +1,21 @@
+-- Domain events written in the transaction of the change that raised them, then delivered
+-- to subscribers by the dispatcher
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    -- status is pending until every subscriber received the event, or dead once attempts ran out
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- delivered_to lists the subscribers that already received the event, so retries skip them
+    delivered_to TEXT[] NOT NULL DEFAULT '{}',
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (next_attempt_at) WHERE status = 'pending';
+
+GRANT INSERT ON outbox_events TO tasks_app;
+GRANT USAGE ON SEQUENCE outbox_events_id_seq TO tasks_app;
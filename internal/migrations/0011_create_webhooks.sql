@@ -0,0 +1,51 @@
+-- Endpoints that receive task events of their workspace
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id),
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    -- secret signs every delivery with HMAC-SHA256
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_workspace_id_idx ON webhooks (workspace_id);
+
+-- The delivery log: one row per event and webhook, retried until it succeeds or attempts run out
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces (id),
+    event_id BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- response_status and last_error describe the latest attempt
+    response_status INTEGER,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+GRANT SELECT, INSERT, DELETE ON webhooks TO tasks_app;
+GRANT USAGE ON SEQUENCE webhooks_id_seq TO tasks_app;
+GRANT SELECT, UPDATE ON webhook_deliveries TO tasks_app;
+
+-- The API only sees the webhooks of the caller's workspace; the sender runs as the owner and sees all
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS webhooks_workspace_isolation ON webhooks;
+CREATE POLICY webhooks_workspace_isolation ON webhooks
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER);
+
+DROP POLICY IF EXISTS webhook_deliveries_workspace_isolation ON webhook_deliveries;
+CREATE POLICY webhook_deliveries_workspace_isolation ON webhook_deliveries
+    USING (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER)
+    WITH CHECK (workspace_id = NULLIF(current_setting('app.workspace_id', true), '')::INTEGER);
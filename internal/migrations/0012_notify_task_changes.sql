@@ -0,0 +1,34 @@
+-- Orders the changes of the task_changes feed across every server instance
+CREATE SEQUENCE IF NOT EXISTS task_changes_seq;
+
+GRANT USAGE ON SEQUENCE task_changes_seq TO tasks_app;
+
+-- Notifies listeners of every write to tasks, whichever server or tool made it. Notifications
+-- are delivered on commit. The payload identifies the task only: it must stay below the 8000
+-- bytes a notification can carry.
+CREATE OR REPLACE FUNCTION notify_task_change() RETURNS TRIGGER AS $$
+DECLARE
+    task tasks;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        task := OLD;
+    ELSE
+        task := NEW;
+    END IF;
+
+    PERFORM pg_notify('task_changes', json_build_object(
+        'changeId', nextval('task_changes_seq'),
+        'op', TG_OP,
+        'id', task.id,
+        'workspaceId', task.workspace_id,
+        'ownerId', task.owner_id,
+        'version', task.version
+    )::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_notify_change ON tasks;
+CREATE TRIGGER tasks_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION notify_task_change();
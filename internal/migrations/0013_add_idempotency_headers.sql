@@ -0,0 +1,2 @@
+-- Headers of the stored response that are replayed with it, such as ETag
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS headers JSONB;
@@ -0,0 +1,6 @@
+-- The single-tenant servers connect as tasks_app within the default workspace, so that
+-- row-level security hides the other workspaces from them. Their pools also keep rate limits
+-- and idempotency keys, and check the schema version.
+GRANT SELECT, INSERT, UPDATE, DELETE ON rate_limit_buckets TO tasks_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON idempotency_keys TO tasks_app;
+GRANT SELECT ON schema_migrations TO tasks_app;
@@ -0,0 +1,9 @@
+-- Buckets are deleted once they have refilled, which takes burst * period / requests and may be
+-- longer than any fixed idle timeout
+ALTER TABLE rate_limit_buckets ADD COLUMN IF NOT EXISTS full_at TIMESTAMPTZ;
+
+-- Existing buckets were kept for an hour after their last request
+UPDATE rate_limit_buckets SET full_at = updated_at + INTERVAL '1 hour' WHERE full_at IS NULL;
+
+DROP INDEX IF EXISTS rate_limit_buckets_updated_at_idx;
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_full_at_idx ON rate_limit_buckets (full_at);
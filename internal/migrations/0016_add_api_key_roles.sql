@@ -0,0 +1,2 @@
+-- Keys act with the roles granted to them, within their scopes. Keys without roles get the default role.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
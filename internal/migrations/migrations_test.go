@@ -0,0 +1,21 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/migrations"
+)
+
+func TestAll(t *testing.T) {
+	all, err := migrations.All()
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+
+	// Versions start at 1 and have no gaps or duplicates
+	for i, migration := range all {
+		require.Equal(t, i+1, migration.Version, migration.Name)
+		require.NotEmpty(t, migration.SQL, migration.Name)
+	}
+	require.Equal(t, all[len(all)-1].Version, migrations.Latest())
+}
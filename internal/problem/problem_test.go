@@ -0,0 +1,50 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+func TestWrite(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	request = request.WithContext(requestid.WithID(request.Context(), "abc-123"))
+	recorder := httptest.NewRecorder()
+
+	problem.Write(recorder, request, problem.New(http.StatusBadRequest, "title is required"))
+
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var body problem.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, problem.Problem{
+		Type:      "about:blank",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "title is required",
+		Instance:  "/tasks",
+		RequestID: "abc-123",
+	}, body)
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	// Extensions are members of the problem, but cannot replace standard members
+	p := problem.New(http.StatusConflict, "task is cancelled")
+	p.Extensions = map[string]any{"allowed": []string{"pending"}, "status": 200}
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Conflict",
+		"status": 409,
+		"detail": "task is cancelled",
+		"allowed": ["pending"]
+	}`, string(data))
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// Bucket is the state of a token bucket
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	// FullAt is when the bucket has refilled, unless more tokens are taken. From then on
+	// it is equivalent to a new bucket, so stores may forget it.
+	FullAt time.Time
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket
+	Limit int
+	// Remaining is the number of requests allowed right now
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, when this one was not
+	RetryAfter time.Duration
+}
+
+// Take refills the bucket for the time elapsed since its last update and takes one token from it.
+// A zero bucket is full.
+func Take(bucket Bucket, limit config.RateLimit, now time.Time) (Bucket, Result) {
+	burst := float64(limit.Burst)
+	perSecond := float64(limit.Requests) / limit.Period.Seconds()
+
+	tokens := burst
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := now.Sub(bucket.UpdatedAt).Seconds()
+		tokens = math.Min(burst, bucket.Tokens+math.Max(0, elapsed)*perSecond)
+	}
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / perSecond)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = seconds((burst - tokens) / perSecond)
+
+	return Bucket{Tokens: tokens, UpdatedAt: now, FullAt: now.Add(result.Reset)}, result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
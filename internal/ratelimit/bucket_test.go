@@ -0,0 +1,76 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/ratelimit"
+)
+
+func TestTake(t *testing.T) {
+	// Create dependencies
+	limit := config.RateLimit{Requests: 60, Period: time.Minute, Burst: 2}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A new bucket is full and allows the burst
+	bucket, result := ratelimit.Take(ratelimit.Bucket{}, limit, now)
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Limit)
+	require.Equal(t, 1, result.Remaining)
+	require.Equal(t, time.Second, result.Reset)
+
+	bucket, result = ratelimit.Take(bucket, limit, now)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	// An empty bucket denies until a token is refilled
+	bucket, result = ratelimit.Take(bucket, limit, now.Add(500*time.Millisecond))
+	require.False(t, result.Allowed)
+	require.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	bucket, result = ratelimit.Take(bucket, limit, now.Add(time.Second))
+	require.True(t, result.Allowed)
+
+	// Refills never exceed the burst
+	_, result = ratelimit.Take(bucket, limit, now.Add(time.Hour))
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+}
+
+func TestMemoryStore(t *testing.T) {
+	// Create dependencies
+	store := ratelimit.NewMemoryStore()
+	limit := config.RateLimit{Requests: 1, Period: time.Minute, Burst: 1}
+	now := time.Now()
+
+	// Every key has its own bucket
+	result, err := store.Take(t.Context(), "alice", limit, now)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = store.Take(t.Context(), "alice", limit, now)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	result, err = store.Take(t.Context(), "bob", limit, now)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// Idle buckets are forgotten without changing the outcome
+	result, err = store.Take(t.Context(), "alice", limit, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// Buckets that refill slowly are kept until they are full
+	slow := config.RateLimit{Requests: 1, Period: time.Hour, Burst: 2}
+	for range 2 {
+		_, err = store.Take(t.Context(), "carol", slow, now)
+		require.NoError(t, err)
+	}
+	result, err = store.Take(t.Context(), "carol", slow, now.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+}
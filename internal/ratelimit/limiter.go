@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// Limiter applies the configured limit of each route to every client separately
+type Limiter struct {
+	config config.RateLimitConfig
+	store  Store
+	now    func() time.Time
+}
+
+func NewLimiter(cfg config.RateLimitConfig, store Store) *Limiter {
+	limiter := Limiter{
+		config: cfg,
+		store:  store,
+		now:    time.Now,
+	}
+
+	return &limiter
+}
+
+// NewStore creates the configured store. The pool is only used by the postgres store.
+func NewStore(cfg config.RateLimitConfig, pool *pgxpool.Pool) Store {
+	if cfg.Store == "postgres" {
+		return NewPostgresStore(pool)
+	}
+	return NewMemoryStore()
+}
+
+// Limit returns the limit of the route, or false when the route is not limited
+func (l *Limiter) Limit(method string, route string) (config.RateLimit, bool) {
+	if !l.config.Enabled || slices.Contains(l.config.ExemptRoutes, route) {
+		return config.RateLimit{}, false
+	}
+	if limit, ok := l.config.Routes[method+" "+route]; ok {
+		return limit, true
+	}
+	return l.config.Default, true
+}
+
+// AddressLimit returns the limit shared by all routes of a client address, or false when the route is not limited
+func (l *Limiter) AddressLimit(route string) (config.RateLimit, bool) {
+	if !l.config.Enabled || slices.Contains(l.config.ExemptRoutes, route) {
+		return config.RateLimit{}, false
+	}
+	return l.config.Address, true
+}
+
+// Take takes a token from the client's bucket for the route
+func (l *Limiter) Take(ctx context.Context, method string, route string, client string, limit config.RateLimit) (Result, error) {
+	return l.store.Take(ctx, method+" "+route+"|"+client, limit, l.now())
+}
+
+// TakeAddress takes a token from the bucket shared by all routes of the client address
+func (l *Limiter) TakeAddress(ctx context.Context, address string, limit config.RateLimit) (Result, error) {
+	return l.store.Take(ctx, "address|"+address, limit, l.now())
+}
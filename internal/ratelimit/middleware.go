@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+)
+
+// KeyFunc identifies the client a request counts against
+type KeyFunc func(r *http.Request) string
+
+// ClientIP keys requests by the address of the caller
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// PrincipalKey keys requests by API key or user, falling back to the address of anonymous callers.
+// It must run after the authentication middleware.
+func PrincipalKey(r *http.Request) string {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	switch {
+	case !ok:
+		return ClientIP(r)
+	case principal.APIKeyID != 0:
+		return "apikey:" + strconv.Itoa(principal.APIKeyID)
+	default:
+		return "user:" + principal.Subject
+	}
+}
+
+// Middleware limits the requests of every client, per route
+func Middleware(limiter *Limiter, route func(*http.Request) string, key KeyFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter.allow(w, r, route(r), key) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// GinMiddleware limits the requests of every client, per route
+func GinMiddleware(limiter *Limiter, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.allow(c.Writer, c.Request, c.FullPath(), key) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GinAddressMiddleware limits the requests of every client address across all routes.
+// It must run before the authentication middleware, so that invalid credentials are limited
+// before they are looked up.
+func GinAddressMiddleware(limiter *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		limit, limited := limiter.AddressLimit(route)
+		if !limited {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.TakeAddress(c.Request.Context(), ClientIP(c.Request), limit)
+		if !limiter.respond(c.Writer, c.Request, limit, result, err) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow takes a token for the request and writes the RateLimit headers.
+// When the client is over its limit it responds with 429 and returns false.
+func (l *Limiter) allow(w http.ResponseWriter, r *http.Request, route string, key KeyFunc) bool {
+	if route == "" {
+		route = "unmatched"
+	}
+
+	limit, limited := l.Limit(r.Method, route)
+	if !limited {
+		return true
+	}
+
+	result, err := l.Take(r.Context(), r.Method, route, key(r), limit)
+	return l.respond(w, r, limit, result, err)
+}
+
+// respond writes the RateLimit headers for the result of taking a token.
+// When the client is over its limit it responds with 429 and returns false.
+func (l *Limiter) respond(w http.ResponseWriter, r *http.Request, limit config.RateLimit, result Result, err error) bool {
+	if err != nil {
+		// An unavailable store must not take the API down with it
+		logging.FromContext(r.Context()).Warn("Rate limit store failed, allowing request", slog.Any("error", err))
+		return true
+	}
+
+	header := w.Header()
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Period.Seconds()), limit.Burst))
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		problem.Write(w, r, problem.New(http.StatusTooManyRequests, "rate limit exceeded, retry later"))
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,126 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+	"github.com/sumup/dependency-injection-go/internal/ratelimit"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	limiter := ratelimit.NewLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Requests: 100, Period: time.Minute, Burst: 100},
+		Routes: map[string]config.RateLimit{
+			"POST /tasks": {Requests: 1, Period: time.Hour, Burst: 2},
+		},
+		ExemptRoutes: []string{"/health"},
+	}, ratelimit.NewMemoryStore())
+
+	router := gin.New()
+	router.Use(ratelimit.GinMiddleware(limiter, ratelimit.ClientIP))
+	router.GET("/health", func(c *gin.Context) {})
+	router.POST("/tasks", func(c *gin.Context) {})
+
+	serve := func(method, path, remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Allowed requests carry the state of the bucket
+	response := serve(http.MethodPost, "/tasks", "10.0.0.1:1234")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, "1;w=3600;burst=2", response.Header().Get("RateLimit-Policy"))
+	require.Equal(t, "2", response.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", response.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "3600", response.Header().Get("RateLimit-Reset"))
+
+	// The client is limited once its burst is used, on any port
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/tasks", "10.0.0.1:1234").Code)
+	response = serve(http.MethodPost, "/tasks", "10.0.0.1:5678")
+	require.Equal(t, http.StatusTooManyRequests, response.Code)
+	require.Equal(t, problem.ContentType, response.Header().Get("Content-Type"))
+	require.Equal(t, "3600", response.Header().Get("Retry-After"))
+	require.Equal(t, "0", response.Header().Get("RateLimit-Remaining"))
+
+	// Other clients have their own buckets
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/tasks", "10.0.0.2:1234").Code)
+
+	// Exempt routes are never limited and carry no headers
+	for range 3 {
+		response = serve(http.MethodGet, "/health", "10.0.0.1:1234")
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Empty(t, response.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestGinAddressMiddleware(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	limiter := ratelimit.NewLimiter(config.RateLimitConfig{
+		Enabled:      true,
+		Default:      config.RateLimit{Requests: 100, Period: time.Minute, Burst: 100},
+		Address:      config.RateLimit{Requests: 1, Period: time.Minute, Burst: 2},
+		ExemptRoutes: []string{"/health"},
+	}, ratelimit.NewMemoryStore())
+
+	router := gin.New()
+	router.Use(ratelimit.GinAddressMiddleware(limiter))
+	router.Use(func(c *gin.Context) {
+		// Stands in for authentication rejecting the credentials
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+	router.GET("/health", func(c *gin.Context) {})
+	router.GET("/tasks", func(c *gin.Context) {})
+	router.POST("/tasks", func(c *gin.Context) {})
+
+	serve := func(method, path, remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Rejected requests still take tokens, from one bucket shared by all routes
+	require.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/tasks", "10.0.0.1:1234").Code)
+	require.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/tasks", "10.0.0.1:1234").Code)
+	response := serve(http.MethodGet, "/tasks", "10.0.0.1:5678")
+	require.Equal(t, http.StatusTooManyRequests, response.Code)
+	require.Equal(t, "60", response.Header().Get("Retry-After"))
+
+	// Other addresses have their own buckets
+	require.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/tasks", "10.0.0.2:1234").Code)
+
+	// Exempt routes are never limited
+	require.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/health", "10.0.0.1:1234").Code)
+}
+
+func TestPrincipalKey(t *testing.T) {
+	// Create dependencies
+	request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+
+	// Anonymous callers are keyed by address
+	require.Equal(t, "ip:10.0.0.1", ratelimit.PrincipalKey(request))
+
+	// Users are keyed by subject
+	ctx := auth.WithPrincipal(request.Context(), auth.Principal{Subject: "alice"})
+	require.Equal(t, "user:alice", ratelimit.PrincipalKey(request.WithContext(ctx)))
+
+	// API keys are keyed separately from their user
+	ctx = auth.WithPrincipal(request.Context(), auth.Principal{Subject: "alice", APIKeyID: 3})
+	require.Equal(t, "apikey:3", ratelimit.PrincipalKey(request.WithContext(ctx)))
+}
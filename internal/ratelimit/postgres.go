@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, shared by every instance
+type PostgresStore struct {
+	pool *pgxpool.Pool
+
+	sweepMutex sync.Mutex
+	lastSweep  time.Time
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	store := PostgresStore{
+		pool: pool,
+	}
+
+	return &store
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit config.RateLimit, now time.Time) (Result, error) {
+	s.sweep(ctx, now)
+
+	var result Result
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		// Lock the bucket so concurrent requests from other instances take tokens one at a time
+		query := `
+			INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, NULL)
+			ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+			RETURNING tokens, updated_at`
+		var bucket Bucket
+		var updatedAt *time.Time
+		if err := tx.QueryRow(ctx, query, key, float64(limit.Burst)).Scan(&bucket.Tokens, &updatedAt); err != nil {
+			return fmt.Errorf("failed to lock rate limit bucket: %w", err)
+		}
+		if updatedAt != nil {
+			bucket.UpdatedAt = *updatedAt
+		}
+
+		bucket, result = Take(bucket, limit, now)
+
+		_, err := tx.Exec(ctx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1`,
+			key, bucket.Tokens, bucket.UpdatedAt, bucket.FullAt)
+		if err != nil {
+			return fmt.Errorf("failed to update rate limit bucket: %w", err)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// sweep deletes buckets that have refilled, at most once per interval and never concurrently
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) {
+	if !s.sweepMutex.TryLock() {
+		return
+	}
+	defer s.sweepMutex.Unlock()
+
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	// A failed sweep is retried at the next interval
+	_, _ = s.pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE full_at <= $1`, now)
+}
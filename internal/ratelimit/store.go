@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// Store keeps the buckets of every client
+type Store interface {
+	// Take takes a token from the bucket stored under key
+	Take(ctx context.Context, key string, limit config.RateLimit, now time.Time) (Result, error)
+}
+
+// sweepInterval is how often stores forget buckets that have refilled
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in the memory of one instance
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]Bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	store := MemoryStore{
+		buckets: map[string]Bucket{},
+	}
+
+	return &store
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit config.RateLimit, now time.Time) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	bucket, result := Take(s.buckets[key], limit, now)
+	s.buckets[key] = bucket
+	return result, nil
+}
+
+// sweep forgets buckets idle long enough to have refilled; they are equivalent to a new bucket
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.FullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
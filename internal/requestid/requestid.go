@@ -0,0 +1,90 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the request ID on requests and responses
+const Header = "X-Request-ID"
+
+// maxLength bounds inbound IDs so callers cannot bloat logs and queries
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a random request ID
+func New() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Valid reports whether an inbound ID is safe to reuse. Only letters, digits and
+// - _ . : are accepted, so the ID can be written to logs and SQL comments verbatim.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		isAlphanumeric := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !isAlphanumeric && !strings.ContainsRune("-_.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID, or an empty string outside of a request
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// GinMiddleware keeps a valid inbound X-Request-ID or generates one, echoes it in
+// the response and attaches it to the request context
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !Valid(id) {
+			id = New()
+		}
+
+		c.Header(Header, id)
+		c.Request = c.Request.WithContext(WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// Middleware is GinMiddleware for net/http handlers
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !Valid(id) {
+			id = New()
+		}
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+	})
+}
+
+// SQLComment appends the request ID to a query as a comment, so Postgres logs can be
+// correlated with API calls. Queries outside of a request are returned unchanged.
+func SQLComment(ctx context.Context, query string) string {
+	id := FromContext(ctx)
+	if !Valid(id) {
+		return query
+	}
+	return query + " /* request_id='" + id + "' */"
+}
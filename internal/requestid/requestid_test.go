@@ -0,0 +1,69 @@
+package requestid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.GinMiddleware())
+
+	var seen string
+	router.GET("/tasks", func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+	})
+
+	tests := []struct {
+		name    string
+		inbound string
+		keep    bool
+	}{
+		{name: "generated when missing", inbound: "", keep: false},
+		{name: "inbound kept", inbound: "client-123_abc.def:1", keep: true},
+		{name: "unsafe inbound replaced", inbound: "x' */ DROP TABLE tasks; --", keep: false},
+		{name: "oversized inbound replaced", inbound: strings.Repeat("a", 129), keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			if tt.inbound != "" {
+				request.Header.Set(requestid.Header, tt.inbound)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			echoed := recorder.Header().Get(requestid.Header)
+			require.True(t, requestid.Valid(echoed))
+			require.Equal(t, echoed, seen)
+			if tt.keep {
+				require.Equal(t, tt.inbound, echoed)
+			} else {
+				require.NotEqual(t, tt.inbound, echoed)
+			}
+		})
+	}
+}
+
+func TestSQLComment(t *testing.T) {
+	query := "SELECT 1"
+
+	require.Equal(t, query, requestid.SQLComment(context.Background(), query))
+
+	ctx := requestid.WithID(context.Background(), "abc-123")
+	require.Equal(t, "SELECT 1 /* request_id='abc-123' */", requestid.SQLComment(ctx, query))
+
+	// IDs that did not pass through the middleware are still never written unescaped
+	ctx = requestid.WithID(context.Background(), "*/ DROP")
+	require.Equal(t, query, requestid.SQLComment(ctx, query))
+}
@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"slices"
+	"sync"
+)
+
+// Message is a change pushed to subscribers
+type Message struct {
+	// ID identifies the message; clients resume after the last ID they received
+	ID    int64
+	Event string
+	Data  any
+}
+
+// Filter selects the messages a subscriber receives
+type Filter func(message Message) bool
+
+// Subscription receives the messages published after it was created
+type Subscription struct {
+	// C is closed when the subscriber falls behind or the broker closes; clients should reconnect and resume
+	C      <-chan Message
+	c      chan Message
+	filter Filter
+}
+
+// Broker fans messages out to subscribers and retains the latest ones so that clients can resume
+type Broker struct {
+	mutex sync.Mutex
+	// history holds the latest messages in the order they were published
+	history     []Message
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroker retains historySize messages for resuming; subscribers that lag more than bufferSize
+// messages behind are dropped
+func NewBroker(historySize int, bufferSize int) *Broker {
+	broker := Broker{
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+
+	return &broker
+}
+
+// Publish sends the message to every subscriber whose filter selects it. It never blocks:
+// subscribers whose buffer is full are dropped.
+func (b *Broker) Publish(message Message) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.history = append(b.history, message)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for subscription := range b.subscribers {
+		if !subscription.filter(message) {
+			continue
+		}
+		select {
+		case subscription.c <- message:
+		default:
+			b.drop(subscription)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. With a lastID it also returns the retained messages published
+// after it; complete is false when that message is no longer retained, so the client has to reload.
+func (b *Broker) Subscribe(lastID int64, filter Filter) (subscription *Subscription, backlog []Message, complete bool) {
+	c := make(chan Message, b.bufferSize)
+	subscription = &Subscription{C: c, c: c, filter: filter}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		close(c)
+		return subscription, nil, false
+	}
+	b.subscribers[subscription] = struct{}{}
+
+	if lastID == 0 {
+		return subscription, nil, true
+	}
+
+	// Messages are resumed in publishing order, which need not follow their IDs
+	last := slices.IndexFunc(b.history, func(message Message) bool { return message.ID == lastID })
+	if last < 0 {
+		return subscription, nil, false
+	}
+	for _, message := range b.history[last+1:] {
+		if filter(message) {
+			backlog = append(backlog, message)
+		}
+	}
+	return subscription, backlog, true
+}
+
+// Unsubscribe stops delivering messages to the subscriber
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[subscription]; ok {
+		b.drop(subscription)
+	}
+}
+
+// Reset forgets the history and drops every subscriber, after messages may have been missed.
+// Clients reconnect, cannot resume, and reload.
+func (b *Broker) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.history = nil
+	for subscription := range b.subscribers {
+		b.drop(subscription)
+	}
+}
+
+// Close drops every subscriber, so that streams end before the server shuts down
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	for subscription := range b.subscribers {
+		b.drop(subscription)
+	}
+}
+
+// drop must be called with the mutex held
+func (b *Broker) drop(subscription *Subscription) {
+	delete(b.subscribers, subscription)
+	close(subscription.c)
+}
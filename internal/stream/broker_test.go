@@ -0,0 +1,80 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/stream"
+)
+
+func all(stream.Message) bool { return true }
+
+func TestBroker_Publish(t *testing.T) {
+	// Create dependencies
+	broker := stream.NewBroker(10, 2)
+	even, _, _ := broker.Subscribe(0, func(message stream.Message) bool { return message.ID%2 == 0 })
+	everything, _, _ := broker.Subscribe(0, all)
+
+	// Subscribers receive the messages their filter selects
+	broker.Publish(stream.Message{ID: 1, Event: "created"})
+	broker.Publish(stream.Message{ID: 2, Event: "updated"})
+	require.Equal(t, int64(2), (<-even.C).ID)
+	require.Equal(t, int64(1), (<-everything.C).ID)
+	require.Equal(t, int64(2), (<-everything.C).ID)
+
+	// Subscribers that fall behind are dropped instead of blocking the publisher
+	for id := int64(3); id <= 6; id++ {
+		broker.Publish(stream.Message{ID: id})
+	}
+	require.Equal(t, int64(3), (<-everything.C).ID)
+	require.Equal(t, int64(4), (<-everything.C).ID)
+	_, open := <-everything.C
+	require.False(t, open)
+
+	// Closing the broker ends the remaining subscriptions
+	require.Equal(t, int64(4), (<-even.C).ID)
+	require.Equal(t, int64(6), (<-even.C).ID)
+	broker.Close()
+	_, open = <-even.C
+	require.False(t, open)
+}
+
+func TestBroker_Subscribe(t *testing.T) {
+	// Create dependencies
+	broker := stream.NewBroker(3, 10)
+	for _, id := range []int64{1, 2, 4, 3, 5} {
+		broker.Publish(stream.Message{ID: id})
+	}
+
+	// Resuming within the history replays the messages published after the last one received
+	_, backlog, complete := broker.Subscribe(4, all)
+	require.True(t, complete)
+	require.Len(t, backlog, 2)
+	require.Equal(t, int64(3), backlog[0].ID)
+	require.Equal(t, int64(5), backlog[1].ID)
+
+	// Up to date clients get no backlog
+	_, backlog, complete = broker.Subscribe(5, all)
+	require.True(t, complete)
+	require.Empty(t, backlog)
+
+	// Resuming from a message that is no longer retained is incomplete
+	_, backlog, complete = broker.Subscribe(2, all)
+	require.False(t, complete)
+	require.Empty(t, backlog)
+
+	// After a reset nothing can be resumed
+	resetSubscription, _, _ := broker.Subscribe(0, all)
+	broker.Reset()
+	_, open := <-resetSubscription.C
+	require.False(t, open)
+	_, _, complete = broker.Subscribe(5, all)
+	require.False(t, complete)
+
+	// Unsubscribed subscribers stop receiving messages
+	subscription, _, _ := broker.Subscribe(0, all)
+	broker.Unsubscribe(subscription)
+	broker.Publish(stream.Message{ID: 6})
+	_, open = <-subscription.C
+	require.False(t, open)
+}
@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sumup/dependency-injection-go/internal/problem"
+)
+
+// Header selects the workspace a request operates on
+const Header = "X-Workspace-ID"
+
+// DefaultWorkspaceID is the workspace used when nothing else selects one
+const DefaultWorkspaceID = 1
+
+type contextKey struct{}
+
+// WithRequestedWorkspace returns a copy of ctx carrying the workspace requested by the caller
+func WithRequestedWorkspace(ctx context.Context, workspaceID int) context.Context {
+	return context.WithValue(ctx, contextKey{}, workspaceID)
+}
+
+// RequestedWorkspace returns the workspace requested by the caller, if any.
+// The request has not been checked against the caller's memberships.
+func RequestedWorkspace(ctx context.Context) (int, bool) {
+	workspaceID, ok := ctx.Value(contextKey{}).(int)
+	return workspaceID, ok
+}
+
+// GinMiddleware reads the requested workspace from the X-Workspace-ID header
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(Header)
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		workspaceID, err := strconv.Atoi(header)
+		if err != nil || workspaceID <= 0 {
+			err = fmt.Errorf("invalid %s header %q", Header, header)
+			_ = c.Error(err)
+			problem.Write(c.Writer, c.Request, problem.New(http.StatusBadRequest, err.Error()))
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithRequestedWorkspace(c.Request.Context(), workspaceID))
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package tenant_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/tenant"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tenant.GinMiddleware())
+
+	var requested int
+	var found bool
+	router.GET("/tasks", func(c *gin.Context) {
+		requested, found = tenant.RequestedWorkspace(c.Request.Context())
+	})
+
+	serve := func(header string) int {
+		request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		if header != "" {
+			request.Header.Set(tenant.Header, header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Without header no workspace is requested
+	require.Equal(t, http.StatusOK, serve(""))
+	require.False(t, found)
+
+	// A valid header is exposed to handlers
+	require.Equal(t, http.StatusOK, serve("7"))
+	require.True(t, found)
+	require.Equal(t, 7, requested)
+
+	// Invalid headers are rejected
+	require.Equal(t, http.StatusBadRequest, serve("abc"))
+	require.Equal(t, http.StatusBadRequest, serve("0"))
+}
@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// ErrForbiddenAddress is returned when an endpoint resolves to an address deliveries may not reach
+var ErrForbiddenAddress = errors.New("forbidden webhook address")
+
+// NewClient returns the client deliveries are sent with. Unless private addresses are allowed,
+// it refuses to connect to loopback, link-local, private and unspecified addresses, so that
+// registered endpoints cannot probe the network of the server. The check runs on the resolved
+// address of every connection, so hostnames resolving to such addresses are refused as well.
+func NewClient(cfg config.WebhooksConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !cfg.AllowPrivateAddresses {
+		dialer.Control = refusePrivateAddresses
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would connect on our behalf, past the address check
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		// Redirects would send the signed payload to an endpoint that was never registered
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func refusePrivateAddresses(network, address string, conn syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrForbiddenAddress, address, err)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/events"
+)
+
+// Envelope is the body of every delivery
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WorkspaceFunc returns the workspace an event belongs to; only its webhooks receive the event
+type WorkspaceFunc func(event events.Event) (int, error)
+
+// Subscriber returns a bus handler that queues a delivery of the event for every webhook of its
+// workspace registered for its type. Queueing twice is a no-op, so outbox retries are safe.
+func Subscriber(pool *pgxpool.Pool, workspaceOf WorkspaceFunc) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		workspaceID, err := workspaceOf(event)
+		if err != nil {
+			return fmt.Errorf("failed to get workspace of event: %w", err)
+		}
+
+		payload, err := json.Marshal(Envelope{
+			ID:        event.ID,
+			Type:      event.Type,
+			CreatedAt: event.CreatedAt,
+			Data:      event.Payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+
+		query := `
+			INSERT INTO webhook_deliveries (webhook_id, workspace_id, event_id, event_type, payload)
+			SELECT id, workspace_id, $1::BIGINT, $2::TEXT, $3::JSONB FROM webhooks
+			WHERE workspace_id = $4 AND $2 = ANY (event_types)
+			ON CONFLICT (webhook_id, event_id) DO NOTHING`
+		if _, err := pool.Exec(ctx, query, event.ID, event.Type, payload, workspaceID); err != nil {
+			return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+		}
+		return nil
+	}
+}
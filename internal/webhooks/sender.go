@@ -0,0 +1,155 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/events"
+)
+
+// Request is a single attempt of a delivery
+type Request struct {
+	DeliveryID int64
+	EventType  string
+	URL        string
+	Secret     string
+	Payload    []byte
+}
+
+// Send posts the signed payload and returns the response status. Any status other than 2xx is an error.
+func Send(ctx context.Context, client *http.Client, request Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tasks-webhooks")
+	req.Header.Set(EventHeader, request.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(request.DeliveryID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(request.Secret, now, request.Payload))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// Drain a bounded part of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("endpoint responded with %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Sender attempts due deliveries. Instances share the delivery log; each delivery is locked by
+// the instance attempting it.
+type Sender struct {
+	pool   *pgxpool.Pool
+	client *http.Client
+	config config.WebhooksConfig
+	logger *slog.Logger
+}
+
+func NewSender(pool *pgxpool.Pool, cfg config.WebhooksConfig, logger *slog.Logger) *Sender {
+	sender := Sender{
+		pool:   pool,
+		client: NewClient(cfg),
+		config: cfg,
+		logger: logger,
+	}
+
+	return &sender
+}
+
+// Run attempts due deliveries until the context is cancelled
+func (s *Sender) Run(ctx context.Context) {
+	for {
+		sent, err := s.SendNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to send webhook delivery", slog.Any("error", err))
+		}
+
+		// Drain due deliveries without waiting, then poll
+		if sent && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+}
+
+// SendNext attempts the oldest due delivery and reports whether there was one
+func (s *Sender) SendNext(ctx context.Context) (bool, error) {
+	sent := false
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		query := `
+			SELECT d.id, d.event_type, d.payload, d.attempts, w.url, w.secret
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT 1
+			FOR UPDATE OF d SKIP LOCKED`
+		var request Request
+		var attempts int
+		err := tx.QueryRow(ctx, query).Scan(&request.DeliveryID, &request.EventType, &request.Payload, &attempts, &request.URL, &request.Secret)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get webhook delivery: %w", err)
+		}
+		sent = true
+		attempts++
+
+		logger := s.logger.With(slog.Int64("delivery_id", request.DeliveryID), slog.String("event_type", request.EventType), slog.Int("attempt", attempts))
+		statusCode, sendErr := Send(ctx, s.client, request)
+		var responseStatus *int
+		if statusCode != 0 {
+			responseStatus = &statusCode
+		}
+		if sendErr == nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE webhook_deliveries SET status = 'succeeded', attempts = $2, response_status = $3, last_error = NULL, delivered_at = NOW()
+				WHERE id = $1`, request.DeliveryID, attempts, responseStatus)
+			if err != nil {
+				return fmt.Errorf("failed to mark webhook delivery succeeded: %w", err)
+			}
+			return nil
+		}
+
+		// Retry later, or give up until the delivery is redelivered manually
+		status := "pending"
+		if attempts >= s.config.MaxAttempts {
+			status = "failed"
+			logger.Error("Webhook delivery failed", slog.Any("error", sendErr))
+		} else {
+			logger.Warn("Webhook delivery attempt failed, retrying", slog.Any("error", sendErr))
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE webhook_deliveries SET status = $2, attempts = $3, response_status = $4, last_error = $5, next_attempt_at = $6
+			WHERE id = $1`, request.DeliveryID, status, attempts, responseStatus, sendErr.Error(),
+			time.Now().Add(events.Backoff(s.config.RetryBackoff, s.config.MaxRetryBackoff, attempts)))
+		if err != nil {
+			return fmt.Errorf("failed to reschedule webhook delivery: %w", err)
+		}
+		return nil
+	})
+	return sent, err
+}
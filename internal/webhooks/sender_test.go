@@ -0,0 +1,112 @@
+package webhooks_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/webhooks"
+)
+
+func TestSend(t *testing.T) {
+	// Create dependencies
+	const secret = "whsec_0123456789abcdef"
+	var received http.Header
+	var receivedBody []byte
+	status := http.StatusNoContent
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// Receivers verify the signature before trusting the body
+		if err := webhooks.Verify(secret, r.Header, body, time.Now(), 5*time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		received, receivedBody = r.Header, body
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+	request := webhooks.Request{
+		DeliveryID: 42,
+		EventType:  "TaskCreated",
+		URL:        receiver.URL,
+		Secret:     secret,
+		Payload:    []byte(`{"id":7,"type":"TaskCreated","data":{}}`),
+	}
+
+	// The receiver gets the signed payload
+	statusCode, err := webhooks.Send(t.Context(), receiver.Client(), request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+	require.Equal(t, request.Payload, receivedBody)
+	require.Equal(t, "TaskCreated", received.Get(webhooks.EventHeader))
+	require.Equal(t, "42", received.Get(webhooks.DeliveryHeader))
+	require.Equal(t, "application/json", received.Get("Content-Type"))
+
+	// A receiver with another secret rejects the delivery
+	request.Secret = "whsec_fedcba9876543210"
+	statusCode, err = webhooks.Send(t.Context(), receiver.Client(), request)
+	require.ErrorContains(t, err, "responded with 401")
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+
+	// Any status other than 2xx fails the attempt
+	request.Secret = secret
+	status = http.StatusServiceUnavailable
+	statusCode, err = webhooks.Send(t.Context(), receiver.Client(), request)
+	require.Error(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, statusCode)
+
+	// Unreachable endpoints fail without a status
+	receiver.Close()
+	statusCode, err = webhooks.Send(t.Context(), http.DefaultClient, request)
+	require.Error(t, err)
+	require.Zero(t, statusCode)
+}
+
+func TestNewClient_PrivateAddresses(t *testing.T) {
+	// Create dependencies
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	cfg := config.Default().Webhooks
+
+	// Endpoints on loopback, link-local and private addresses are refused before connecting
+	client := webhooks.NewClient(cfg)
+	for _, url := range []string{receiver.URL, "http://169.254.169.254/latest/meta-data", "http://10.0.0.1:5432", "http://[::1]:8080", "http://0.0.0.0"} {
+		statusCode, err := webhooks.Send(t.Context(), client, webhooks.Request{URL: url, Payload: []byte(`{}`)})
+		require.ErrorIs(t, err, webhooks.ErrForbiddenAddress, url)
+		require.Zero(t, statusCode)
+	}
+
+	// unless they are allowed
+	cfg.AllowPrivateAddresses = true
+	statusCode, err := webhooks.Send(t.Context(), webhooks.NewClient(cfg), webhooks.Request{URL: receiver.URL, Payload: []byte(`{}`)})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+}
+
+func TestVerify(t *testing.T) {
+	// Create dependencies
+	const secret = "whsec_0123456789abcdef"
+	body := []byte(`{"id":7}`)
+	sentAt := time.Unix(1_700_000_000, 0)
+	header := http.Header{}
+	header.Set(webhooks.TimestampHeader, strconv.FormatInt(sentAt.Unix(), 10))
+	header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, sentAt, body))
+
+	// Accepted within the tolerance
+	require.NoError(t, webhooks.Verify(secret, header, body, sentAt.Add(time.Minute), 5*time.Minute))
+
+	// Replays outside of the tolerance are rejected
+	require.ErrorIs(t, webhooks.Verify(secret, header, body, sentAt.Add(10*time.Minute), 5*time.Minute), webhooks.ErrExpiredTimestamp)
+
+	// Tampered bodies and timestamps are rejected
+	require.ErrorIs(t, webhooks.Verify(secret, header, []byte(`{"id":8}`), sentAt, 5*time.Minute), webhooks.ErrInvalidSignature)
+	header.Set(webhooks.TimestampHeader, strconv.FormatInt(sentAt.Unix()+1, 10))
+	require.ErrorIs(t, webhooks.Verify(secret, header, body, sentAt, 5*time.Minute), webhooks.ErrInvalidSignature)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/authz"
+)
+
+// runAPIKeyCommand manages the API keys used by scripts and services
+func runAPIKeyCommand(ctx context.Context, conn *pgx.Conn, args []string) error {
+	usage := errors.New("Usage: manage apikey create -user <username> -name <name> [-workspace <id>] [-scopes tasks:read,tasks:write] [-roles admin] [-expires 720h] | manage apikey revoke <id> | manage apikey list")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(ctx, conn, args[1:])
+	case "revoke":
+		if len(args) != 2 {
+			return usage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("Invalid API key id %q", args[1])
+		}
+		return revokeAPIKey(ctx, conn, id)
+	case "list":
+		return listAPIKeys(ctx, conn)
+	default:
+		return fmt.Errorf("Unknown apikey command %q", args[0])
+	}
+}
+
+func createAPIKey(ctx context.Context, conn *pgx.Conn, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	username := flags.String("user", "", "user the key acts as")
+	name := flags.String("name", "", "name describing what the key is used for")
+	workspaceID := flags.Int("workspace", 1, "workspace the key is confined to")
+	scopes := flags.String("scopes", "tasks:read", "comma separated scopes")
+	roles := flags.String("roles", "", "comma separated roles, the default role when empty")
+	expires := flags.Duration("expires", 0, "lifetime of the key, 0 for no expiry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" || *name == "" {
+		return errors.New("-user and -name are required")
+	}
+
+	scopeList := strings.Split(*scopes, ",")
+	for _, scope := range scopeList {
+		if _, ok := authz.Scopes[scope]; !ok {
+			return fmt.Errorf("Unknown scope %q", scope)
+		}
+	}
+
+	roleList := []string{}
+	if *roles != "" {
+		roleList = strings.Split(*roles, ",")
+	}
+
+	var expiresAt *time.Time
+	if *expires > 0 {
+		at := time.Now().UTC().Add(*expires)
+		expiresAt = &at
+	}
+
+	key, prefix, hash := auth.GenerateAPIKey()
+
+	var id int
+	err := conn.QueryRow(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, user_id, workspace_id, scopes, roles, expires_at)
+		SELECT $1, $2, $3, id, $5, $6, $7, $8 FROM users WHERE username = $4
+		RETURNING id`, *name, prefix, hash, *username, *workspaceID, scopeList, roleList, expiresAt).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("Unknown user %q", *username)
+	}
+	if err != nil {
+		return fmt.Errorf("Error creating API key: %w", err)
+	}
+
+	fmt.Printf("API key %d created for %s. Store it now, it cannot be shown again:\n%s\n", id, *username, key)
+	return nil
+}
+
+func revokeAPIKey(ctx context.Context, conn *pgx.Conn, id int) error {
+	tag, err := conn.Exec(ctx, `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("Error revoking API key: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		fmt.Printf("API key %d was not revoked: unknown or already revoked\n", id)
+		return nil
+	}
+	fmt.Printf("API key %d revoked\n", id)
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `
+		SELECT k.id, k.name, k.prefix, u.username, k.workspace_id, k.scopes, k.roles, k.expires_at, k.last_used_at, k.revoked_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		ORDER BY k.id`)
+	if err != nil {
+		return fmt.Errorf("Error listing API keys: %w", err)
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tUSER\tWORKSPACE\tSCOPES\tROLES\tEXPIRES AT\tLAST USED AT\tSTATUS")
+	now := time.Now().UTC()
+	for rows.Next() {
+		var (
+			id, workspaceID                  int
+			name, prefix, username           string
+			scopes, roles                    []string
+			expiresAt, lastUsedAt, revokedAt *time.Time
+		)
+		if err := rows.Scan(&id, &name, &prefix, &username, &workspaceID, &scopes, &roles, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+			return fmt.Errorf("Error reading API key: %w", err)
+		}
+
+		status := "active"
+		if revokedAt != nil {
+			status = "revoked"
+		} else if expiresAt != nil && !now.Before(*expiresAt) {
+			status = "expired"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", id, name, prefix, username, workspaceID,
+			strings.Join(scopes, ","), strings.Join(roles, ","), formatTime(expiresAt), formatTime(lastUsedAt), status)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error listing API keys: %w", err)
+	}
+
+	return w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/migrations"
+)
+
+// expectedSchemaName is the scratch schema the migrations are replayed into
+// so the live schema can be compared against them. It only ever exists inside
+// a transaction that is rolled back.
+const expectedSchemaName = "doctor_expected"
+
+// slowPingThreshold is the average round trip above which latency is reported as a warning
+const slowPingThreshold = 50 * time.Millisecond
+
+// checkStatus represents the outcome of a single doctor check
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+// checkResult is a single line of the doctor report
+type checkResult struct {
+	Status checkStatus
+	Name   string
+	Detail string
+}
+
+// doctor runs diagnostics against the configuration and the database and collects the results
+type doctor struct {
+	config  config.Config
+	results []checkResult
+}
+
+func newDoctor() *doctor {
+	return &doctor{}
+}
+
+func (d *doctor) report(status checkStatus, name string, format string, args ...any) {
+	d.results = append(d.results, checkResult{
+		Status: status,
+		Name:   name,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// Run executes every check and returns false if any of them failed
+func (d *doctor) Run(ctx context.Context) bool {
+	if d.checkConfig() {
+		conn, ok := d.checkConnectivity(ctx)
+		if ok {
+			defer conn.Close(context.Background())
+
+			d.checkLatency(ctx, conn)
+			d.checkMigrations(ctx, conn)
+			d.checkSchema(ctx, conn)
+		}
+	}
+
+	return d.print()
+}
+
+// checkConfig loads the configuration the servers use and reports what they expect
+func (d *doctor) checkConfig() bool {
+	cfg, err := config.Read()
+	if err != nil {
+		d.report(checkFail, "config", "%v", err)
+		return false
+	}
+	d.config = cfg
+
+	source := "defaults and environment"
+	if cfg.File != "" {
+		source = cfg.File + ", defaults and environment"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			d.report(checkFail, "config", "%s", line)
+		}
+		return false
+	}
+
+	d.report(checkPass, "config", "loaded from %s", source)
+	d.report(checkPass, "config", "servers listen on %s, allowed origins %s", cfg.HTTP.Addr, strings.Join(cfg.CORS.AllowedOrigins, ", "))
+	d.report(checkPass, "config", "database host=%s port=%d user=%s dbname=%s sslmode=%s pool=%d-%d",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Name, cfg.Database.SSLMode,
+		cfg.Database.MinConns, cfg.Database.MaxConns)
+	return true
+}
+
+func (d *doctor) checkConnectivity(ctx context.Context) (*pgx.Conn, bool) {
+	start := time.Now()
+	conn, err := database.Connect(ctx, d.config.Database)
+	if err != nil {
+		d.report(checkFail, "connectivity", "unable to connect: %v", err)
+		return nil, false
+	}
+
+	var version string
+	if err := conn.QueryRow(ctx, "SHOW server_version").Scan(&version); err != nil {
+		conn.Close(context.Background())
+		d.report(checkFail, "connectivity", "connected but unable to query server: %v", err)
+		return nil, false
+	}
+
+	d.report(checkPass, "connectivity", "connected in %s (PostgreSQL %s)", time.Since(start).Round(time.Microsecond), version)
+	return conn, true
+}
+
+func (d *doctor) checkLatency(ctx context.Context, conn *pgx.Conn) {
+	const pings = 5
+
+	var total, fastest, slowest time.Duration
+	for i := 0; i < pings; i++ {
+		start := time.Now()
+		if err := conn.Ping(ctx); err != nil {
+			d.report(checkFail, "latency", "ping failed: %v", err)
+			return
+		}
+		elapsed := time.Since(start)
+
+		total += elapsed
+		if i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+		if elapsed > slowest {
+			slowest = elapsed
+		}
+	}
+
+	average := total / pings
+	status := checkPass
+	if average > slowPingThreshold {
+		status = checkWarn
+	}
+	d.report(status, "latency", "avg %s over %d pings (min %s, max %s)",
+		average.Round(time.Microsecond), pings, fastest.Round(time.Microsecond), slowest.Round(time.Microsecond))
+}
+
+func (d *doctor) checkMigrations(ctx context.Context, conn *pgx.Conn) {
+	version, err := migrations.CurrentVersion(ctx, conn)
+	if err != nil {
+		d.report(checkFail, "migrations", "%v", err)
+		return
+	}
+
+	latest := migrations.Latest()
+	switch {
+	case version < latest:
+		d.report(checkFail, "migrations", "schema is at version %d, expected %d; run `manage migrate`", version, latest)
+	case version > latest:
+		d.report(checkWarn, "migrations", "schema is at version %d, newer than this build (%d)", version, latest)
+	default:
+		d.report(checkPass, "migrations", "schema is at version %d", version)
+	}
+}
+
+// checkSchema replays the migrations into a scratch schema and compares the result with the live one
+func (d *doctor) checkSchema(ctx context.Context, conn *pgx.Conn) {
+	var liveSchemaName string
+	if err := conn.QueryRow(ctx, "SELECT current_schema()").Scan(&liveSchemaName); err != nil {
+		d.report(checkFail, "schema", "unable to determine current schema: %v", err)
+		return
+	}
+
+	live, err := inspectSchema(ctx, conn, liveSchemaName)
+	if err != nil {
+		d.report(checkFail, "schema", "unable to inspect live schema: %v", err)
+		return
+	}
+
+	expected, err := expectedSchema(ctx, conn)
+	if err != nil {
+		d.report(checkFail, "schema", "unable to build expected schema from migrations: %v", err)
+		return
+	}
+
+	problems := diffSchemas(expected, live)
+	if len(problems) == 0 {
+		d.report(checkPass, "schema", "%d tables match the migrations", len(expected.Tables))
+		return
+	}
+
+	for _, problem := range problems {
+		d.report(problem.Status, problem.Name, "%s", problem.Detail)
+	}
+}
+
+// print writes the report to stdout and returns false if any check failed
+func (d *doctor) print() bool {
+	counts := map[checkStatus]int{}
+	for _, result := range d.results {
+		counts[result.Status]++
+		fmt.Printf("[%s] %-12s %s\n", result.Status, result.Name, result.Detail)
+	}
+
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", counts[checkPass], counts[checkWarn], counts[checkFail])
+	return counts[checkFail] == 0
+}
+
+// schemaSnapshot describes the tables of a schema in a comparable form
+type schemaSnapshot struct {
+	Tables map[string]*tableSnapshot
+}
+
+type tableSnapshot struct {
+	Columns     map[string]columnSnapshot
+	Indexes     map[string]string
+	Constraints map[string]string
+}
+
+type columnSnapshot struct {
+	DataType string
+	Nullable bool
+	Default  string
+}
+
+func (s *schemaSnapshot) table(name string) *tableSnapshot {
+	table, ok := s.Tables[name]
+	if !ok {
+		table = &tableSnapshot{
+			Columns:     map[string]columnSnapshot{},
+			Indexes:     map[string]string{},
+			Constraints: map[string]string{},
+		}
+		s.Tables[name] = table
+	}
+	return table
+}
+
+// expectedSchema applies the migrations inside a throwaway schema and inspects the result.
+// The transaction is always rolled back so nothing is left behind.
+func expectedSchema(ctx context.Context, conn *pgx.Conn) (schemaSnapshot, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return schemaSnapshot{}, err
+	}
+	defer tx.Rollback(context.Background())
+
+	if _, err := tx.Exec(ctx, "CREATE SCHEMA "+expectedSchemaName); err != nil {
+		return schemaSnapshot{}, err
+	}
+	if _, err := tx.Exec(ctx, "SET LOCAL search_path TO "+expectedSchemaName); err != nil {
+		return schemaSnapshot{}, err
+	}
+
+	// Never run the migrations unless they are guaranteed to land in the scratch schema
+	var currentSchema string
+	if err := tx.QueryRow(ctx, "SELECT current_schema()").Scan(&currentSchema); err != nil {
+		return schemaSnapshot{}, err
+	}
+	if currentSchema != expectedSchemaName {
+		return schemaSnapshot{}, fmt.Errorf("refusing to apply migrations to schema %q", currentSchema)
+	}
+
+	if _, err := migrations.Apply(ctx, tx); err != nil {
+		return schemaSnapshot{}, err
+	}
+
+	return inspectSchema(ctx, tx, expectedSchemaName)
+}
+
+// querier is the subset of pgx.Conn and pgx.Tx used to inspect a schema
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// inspectSchema reads columns, indexes and constraints of every table in the given schema.
+// Schema qualifiers are stripped so snapshots of different schemas can be compared.
+func inspectSchema(ctx context.Context, q querier, schema string) (schemaSnapshot, error) {
+	snapshot := schemaSnapshot{Tables: map[string]*tableSnapshot{}}
+	unqualify := strings.NewReplacer(schema+".", "", `"`+schema+`".`, "").Replace
+
+	columns, err := q.Query(ctx, `
+		SELECT table_name, column_name, data_type, is_nullable = 'YES', COALESCE(column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = $1`, schema)
+	if err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer columns.Close()
+	for columns.Next() {
+		var table, name string
+		var column columnSnapshot
+		if err := columns.Scan(&table, &name, &column.DataType, &column.Nullable, &column.Default); err != nil {
+			return schemaSnapshot{}, fmt.Errorf("failed to scan column: %w", err)
+		}
+		column.Default = unqualify(column.Default)
+		snapshot.table(table).Columns[name] = column
+	}
+	if err := columns.Err(); err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	indexes, err := q.Query(ctx, `SELECT tablename, indexname, indexdef FROM pg_indexes WHERE schemaname = $1`, schema)
+	if err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer indexes.Close()
+	for indexes.Next() {
+		var table, name, definition string
+		if err := indexes.Scan(&table, &name, &definition); err != nil {
+			return schemaSnapshot{}, fmt.Errorf("failed to scan index: %w", err)
+		}
+		snapshot.table(table).Indexes[name] = unqualify(definition)
+	}
+	if err := indexes.Err(); err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	constraints, err := q.Query(ctx, `
+		SELECT rel.relname, con.conname, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_namespace nsp ON nsp.oid = rel.relnamespace
+		WHERE nsp.nspname = $1`, schema)
+	if err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read constraints: %w", err)
+	}
+	defer constraints.Close()
+	for constraints.Next() {
+		var table, name, definition string
+		if err := constraints.Scan(&table, &name, &definition); err != nil {
+			return schemaSnapshot{}, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		snapshot.table(table).Constraints[name] = unqualify(definition)
+	}
+	if err := constraints.Err(); err != nil {
+		return schemaSnapshot{}, fmt.Errorf("failed to read constraints: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// diffSchemas lists every difference between the expected and the live schema.
+// Anything missing or different is a failure; extra objects in the live schema are warnings.
+func diffSchemas(expected, live schemaSnapshot) []checkResult {
+	var problems []checkResult
+	fail := func(name, format string, args ...any) {
+		problems = append(problems, checkResult{Status: checkFail, Name: name, Detail: fmt.Sprintf(format, args...)})
+	}
+	warn := func(name, format string, args ...any) {
+		problems = append(problems, checkResult{Status: checkWarn, Name: name, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	for _, tableName := range sortedKeys(expected.Tables) {
+		want := expected.Tables[tableName]
+		got, ok := live.Tables[tableName]
+		if !ok {
+			fail("schema", "table %s is missing", tableName)
+			continue
+		}
+
+		for _, name := range sortedKeys(want.Columns) {
+			wantColumn := want.Columns[name]
+			gotColumn, ok := got.Columns[name]
+			switch {
+			case !ok:
+				fail("schema", "column %s.%s is missing", tableName, name)
+			case gotColumn.DataType != wantColumn.DataType:
+				fail("schema", "column %s.%s has type %s, expected %s", tableName, name, gotColumn.DataType, wantColumn.DataType)
+			case gotColumn.Nullable != wantColumn.Nullable:
+				fail("schema", "column %s.%s nullable=%t, expected nullable=%t", tableName, name, gotColumn.Nullable, wantColumn.Nullable)
+			case gotColumn.Default != wantColumn.Default:
+				fail("schema", "column %s.%s has default %q, expected %q", tableName, name, gotColumn.Default, wantColumn.Default)
+			}
+		}
+		for _, name := range sortedKeys(got.Columns) {
+			if _, ok := want.Columns[name]; !ok {
+				warn("schema", "column %s.%s is not part of the migrations", tableName, name)
+			}
+		}
+
+		for _, name := range sortedKeys(want.Indexes) {
+			gotDefinition, ok := got.Indexes[name]
+			switch {
+			case !ok:
+				fail("indexes", "index %s on %s is missing", name, tableName)
+			case gotDefinition != want.Indexes[name]:
+				fail("indexes", "index %s is %q, expected %q", name, gotDefinition, want.Indexes[name])
+			}
+		}
+		for _, name := range sortedKeys(got.Indexes) {
+			if _, ok := want.Indexes[name]; !ok {
+				warn("indexes", "index %s on %s is not part of the migrations", name, tableName)
+			}
+		}
+
+		for _, name := range sortedKeys(want.Constraints) {
+			gotDefinition, ok := got.Constraints[name]
+			switch {
+			case !ok:
+				fail("constraints", "constraint %s on %s is missing", name, tableName)
+			case gotDefinition != want.Constraints[name]:
+				fail("constraints", "constraint %s is %q, expected %q", name, gotDefinition, want.Constraints[name])
+			}
+		}
+		for _, name := range sortedKeys(got.Constraints) {
+			if _, ok := want.Constraints[name]; !ok {
+				warn("constraints", "constraint %s on %s is not part of the migrations", name, tableName)
+			}
+		}
+	}
+
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
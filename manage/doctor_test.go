@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	// Build the schema produced by the migrations
+	expected := schemaSnapshot{Tables: map[string]*tableSnapshot{}}
+	tasks := expected.table("tasks")
+	tasks.Columns["id"] = columnSnapshot{DataType: "integer", Default: "nextval('tasks_id_seq'::regclass)"}
+	tasks.Columns["title"] = columnSnapshot{DataType: "text"}
+	tasks.Columns["created_at"] = columnSnapshot{DataType: "timestamp without time zone", Default: "CURRENT_TIMESTAMP"}
+	tasks.Indexes["tasks_pkey"] = "CREATE UNIQUE INDEX tasks_pkey ON tasks USING btree (id)"
+	tasks.Constraints["tasks_pkey"] = "PRIMARY KEY (id)"
+
+	// An identical live schema has no problems
+	require.Empty(t, diffSchemas(expected, expected))
+
+	// Build a live schema that drifted from the migrations
+	live := schemaSnapshot{Tables: map[string]*tableSnapshot{}}
+	liveTasks := live.table("tasks")
+	liveTasks.Columns["id"] = tasks.Columns["id"]
+	liveTasks.Columns["title"] = columnSnapshot{DataType: "character varying"}
+	liveTasks.Columns["legacy"] = columnSnapshot{DataType: "text", Nullable: true}
+	liveTasks.Constraints["tasks_pkey"] = "PRIMARY KEY (id)"
+
+	problems := diffSchemas(expected, live)
+
+	require.Equal(t, []checkResult{
+		{Status: checkFail, Name: "schema", Detail: "column tasks.created_at is missing"},
+		{Status: checkFail, Name: "schema", Detail: "column tasks.title has type character varying, expected text"},
+		{Status: checkWarn, Name: "schema", Detail: "column tasks.legacy is not part of the migrations"},
+		{Status: checkFail, Name: "indexes", Detail: "index tasks_pkey on tasks is missing"},
+	}, problems)
+
+	// A missing table is reported once
+	problems = diffSchemas(expected, schemaSnapshot{Tables: map[string]*tableSnapshot{}})
+	require.Equal(t, []checkResult{
+		{Status: checkFail, Name: "schema", Detail: "table tasks is missing"},
+	}, problems)
+}
@@ -2,17 +2,18 @@ package main
 
 import (
 	"context"
-	_ "embed"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/migrations"
+	"go.uber.org/dig"
 )
 
-//go:embed tasks.sql
-var sqlContent string
-
 func main() {
 
 	command := "migrate"
@@ -22,32 +23,86 @@ func main() {
 
 	fmt.Printf("Running %s command\n", command)
 
-	// Database connection parameters (same as in main.go)
-	connStr := "host=localhost port=5433 user=postgres password=postgres dbname=postgres sslmode=disable"
+	// The doctor reports configuration and connection problems itself instead of aborting
+	if command == "doctor" {
+		if !newDoctor().Run(context.Background()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Issuing a token only needs the configuration
+	if command == "token" {
+		if len(os.Args) < 3 {
+			log.Fatalf("Usage: manage token <subject> [roles]\n")
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		token, err := issueToken(cfg.Auth, os.Args[2], strings.Join(os.Args[3:], ","))
+		if err != nil {
+			log.Fatalf("Error issuing token: %v\n", err)
+		}
+		fmt.Println(token)
+		return
+	}
 
+	// Register dependencies
+	container := dig.New()
+	for _, provider := range []any{config.Load, newConnection} {
+		if err := container.Provide(provider); err != nil {
+			log.Fatalf("Unable to register %T: %v\n", provider, err)
+		}
+	}
+
+	err := container.Invoke(func(conn *pgx.Conn) error {
+		defer conn.Close(context.Background())
+		return runCommand(command, os.Args[2:], conn)
+	})
+	if err != nil {
+		log.Fatalf("%v\n", dig.RootCause(err))
+	}
+}
+
+func newConnection(cfg config.Config) (*pgx.Conn, error) {
 	// Connect to the database
-	conn, err := pgx.Connect(context.Background(), connStr)
+	conn, err := database.Connect(context.Background(), cfg.Database)
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v\n", err)
+		return nil, fmt.Errorf("Unable to connect to database: %w", err)
 	}
-	defer conn.Close(context.Background())
+	return conn, nil
+}
 
+func runCommand(command string, args []string, conn *pgx.Conn) error {
 	if command == "migrate" {
-		// Execute the SQL
-		_, err = conn.Exec(context.Background(), string(sqlContent))
+		// Apply the pending migrations
+		applied, err := migrations.Apply(context.Background(), conn)
+		for _, migration := range applied {
+			fmt.Printf("Applied %s\n", migration.Name)
+		}
 		if err != nil {
-			log.Fatalf("Error executing SQL: %v\n", err)
+			return fmt.Errorf("Error applying migrations: %w", err)
 		}
 
-		fmt.Println("Migration completed successfully!")
+		fmt.Printf("Migration completed successfully! Schema is at version %d\n", migrations.Latest())
 	} else if command == "clean" {
 		// Execute the clean command to delete all tasks
-		_, err = conn.Exec(context.Background(), "DELETE FROM tasks")
+		_, err := conn.Exec(context.Background(), "DELETE FROM tasks")
 		if err != nil {
-			log.Fatalf("Error clearing tasks table: %v\n", err)
+			return fmt.Errorf("Error clearing tasks table: %w", err)
 		}
 
 		fmt.Println("Tasks table cleared successfully!")
+	} else if command == "user" {
+		return runUserCommand(context.Background(), conn, args)
+	} else if command == "workspace" {
+		return runWorkspaceCommand(context.Background(), conn, args)
+	} else if command == "apikey" {
+		return runAPIKeyCommand(context.Background(), conn, args)
+	} else if command == "outbox" {
+		return runOutboxCommand(context.Background(), conn, args)
 	}
 
+	return nil
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// runOutboxCommand inspects dead-lettered domain events and queues them for another delivery
+func runOutboxCommand(ctx context.Context, conn *pgx.Conn, args []string) error {
+	usage := errors.New("Usage: manage outbox list | manage outbox retry <event id>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "list":
+		return listDeadEvents(ctx, conn)
+	case "retry":
+		if len(args) != 2 {
+			return usage
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid event id %q", args[1])
+		}
+		return retryEvent(ctx, conn, id)
+	default:
+		return fmt.Errorf("Unknown outbox command %q", args[0])
+	}
+}
+
+func listDeadEvents(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `
+		SELECT id, type, attempts, created_at, coalesce(last_error, '')
+		FROM outbox_events
+		WHERE status = 'dead'
+		ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("Error listing dead events: %w", err)
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tATTEMPTS\tCREATED\tLAST ERROR")
+	for rows.Next() {
+		var (
+			id        int64
+			eventType string
+			attempts  int
+			createdAt time.Time
+			lastError string
+		)
+		if err := rows.Scan(&id, &eventType, &attempts, &createdAt, &lastError); err != nil {
+			return fmt.Errorf("Error reading event: %w", err)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", id, eventType, attempts, createdAt.Format(time.RFC3339), lastError)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error listing dead events: %w", err)
+	}
+	return w.Flush()
+}
+
+// retryEvent gives a dead event a fresh set of attempts; subscribers that received it are skipped
+func retryEvent(ctx context.Context, conn *pgx.Conn, id int64) error {
+	tag, err := conn.Exec(ctx, `
+		UPDATE outbox_events SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'dead'`, id)
+	if err != nil {
+		return fmt.Errorf("Error retrying event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("No dead event with id %d", id)
+	}
+
+	fmt.Printf("Event %d queued for delivery\n", id)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/config"
+)
+
+// tokenTTL is the lifetime of tokens issued for local development
+const tokenTTL = 24 * time.Hour
+
+// issueToken signs an HS256 token for the subject with the configured secret.
+// Roles are given as a comma separated list.
+func issueToken(cfg config.AuthConfig, subject string, roles string) (string, error) {
+	if cfg.HMACSecret == "" {
+		return "", errors.New("auth.hmacSecret must be set to issue tokens")
+	}
+
+	now := time.Now()
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    cfg.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	}
+	if cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{cfg.Audience}
+	}
+	if roles != "" {
+		claims.Roles = strings.Split(roles, ",")
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.HMACSecret))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// runUserCommand manages the users that tasks belong to.
+// The username must match the subject of the user's tokens.
+func runUserCommand(ctx context.Context, conn *pgx.Conn, args []string) error {
+	if len(args) == 0 {
+		return errors.New("Usage: manage user create <username> | manage user list")
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 || args[1] == "" {
+			return errors.New("Usage: manage user create <username>")
+		}
+		return createUser(ctx, conn, args[1])
+	case "list":
+		return listUsers(ctx, conn)
+	default:
+		return fmt.Errorf("Unknown user command %q", args[0])
+	}
+}
+
+func createUser(ctx context.Context, conn *pgx.Conn, username string) error {
+	var id int
+	err := conn.QueryRow(ctx, `INSERT INTO users (username) VALUES ($1) RETURNING id`, username).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("Error creating user: %w", err)
+	}
+
+	fmt.Printf("User %s created with id %d\n", username, id)
+	return nil
+}
+
+func listUsers(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `SELECT id, username, created_at FROM users ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("Error listing users: %w", err)
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tCREATED AT")
+	for rows.Next() {
+		var (
+			id        int
+			username  string
+			createdAt time.Time
+		)
+		if err := rows.Scan(&id, &username, &createdAt); err != nil {
+			return fmt.Errorf("Error reading user: %w", err)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", id, username, createdAt.Format(time.RFC3339))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error listing users: %w", err)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// runWorkspaceCommand manages workspaces and the users that belong to them
+func runWorkspaceCommand(ctx context.Context, conn *pgx.Conn, args []string) error {
+	usage := errors.New("Usage: manage workspace create <name> | manage workspace list | manage workspace add <workspace id> <username>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 || args[1] == "" {
+			return usage
+		}
+		return createWorkspace(ctx, conn, args[1])
+	case "list":
+		return listWorkspaces(ctx, conn)
+	case "add":
+		if len(args) != 3 {
+			return usage
+		}
+		workspaceID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("Invalid workspace id %q", args[1])
+		}
+		return addWorkspaceMember(ctx, conn, workspaceID, args[2])
+	default:
+		return fmt.Errorf("Unknown workspace command %q", args[0])
+	}
+}
+
+func createWorkspace(ctx context.Context, conn *pgx.Conn, name string) error {
+	var id int
+	err := conn.QueryRow(ctx, `INSERT INTO workspaces (name) VALUES ($1) RETURNING id`, name).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("Error creating workspace: %w", err)
+	}
+
+	fmt.Printf("Workspace %s created with id %d\n", name, id)
+	return nil
+}
+
+func listWorkspaces(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `
+		SELECT w.id, w.name, count(m.user_id)
+		FROM workspaces w
+		LEFT JOIN workspace_members m ON m.workspace_id = w.id
+		GROUP BY w.id, w.name
+		ORDER BY w.id`)
+	if err != nil {
+		return fmt.Errorf("Error listing workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tMEMBERS")
+	for rows.Next() {
+		var (
+			id      int
+			name    string
+			members int
+		)
+		if err := rows.Scan(&id, &name, &members); err != nil {
+			return fmt.Errorf("Error reading workspace: %w", err)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\n", id, name, members)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error listing workspaces: %w", err)
+	}
+
+	return w.Flush()
+}
+
+func addWorkspaceMember(ctx context.Context, conn *pgx.Conn, workspaceID int, username string) error {
+	tag, err := conn.Exec(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id)
+		SELECT $1, id FROM users WHERE username = $2
+		ON CONFLICT DO NOTHING`, workspaceID, username)
+	if err != nil {
+		return fmt.Errorf("Error adding workspace member: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		fmt.Printf("User %s was not added: unknown user or already a member of workspace %d\n", username, workspaceID)
+		return nil
+	}
+	fmt.Printf("User %s added to workspace %d\n", username, workspaceID)
+	return nil
+}
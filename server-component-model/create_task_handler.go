@@ -1,13 +1,20 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
 
 type CreateTaskHandler struct {
 	repository *Repository
 }
 
-func NewCreateTaskHandler() (*CreateTaskHandler, error) {
-	repository, err := NewRepository()
+func NewCreateTaskHandler(metrics *metrics.Metrics) (*CreateTaskHandler, error) {
+	repository, err := NewRepository(metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -26,7 +33,7 @@ type CreateTaskOutput struct {
 	Task Task `json:"task"`
 }
 
-func (h *CreateTaskHandler) Handle(input CreateTaskInput) (CreateTaskOutput, error) {
+func (h *CreateTaskHandler) Handle(ctx context.Context, input CreateTaskInput) (CreateTaskOutput, error) {
 
 	// Validate required fields
 	if input.Title == "" {
@@ -40,10 +47,11 @@ func (h *CreateTaskHandler) Handle(input CreateTaskInput) (CreateTaskOutput, err
 	}
 
 	// Create the task using repository
-	createdTask, err := h.repository.CreateTask(task)
+	createdTask, err := h.repository.CreateTask(ctx, task)
 	if err != nil {
 		return CreateTaskOutput{}, fmt.Errorf("failed to create task: %w", err)
 	}
+	logging.FromContext(ctx).Info("Task created", slog.Int("task_id", createdTask.ID))
 
 	return CreateTaskOutput{
 		Task: createdTask,
@@ -1,6 +1,13 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
 
 type GetTasksHandler struct {
 	repository *Repository
@@ -10,8 +17,8 @@ type GetTasksOutput struct {
 	Tasks []Task `json:"tasks"`
 }
 
-func NewGetTasksHandler() (*GetTasksHandler, error) {
-	repository, err := NewRepository()
+func NewGetTasksHandler(metrics *metrics.Metrics) (*GetTasksHandler, error) {
+	repository, err := NewRepository(metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -21,11 +28,12 @@ func NewGetTasksHandler() (*GetTasksHandler, error) {
 	return &handler, nil
 }
 
-func (h *GetTasksHandler) Handle() (GetTasksOutput, error) {
-	tasks, err := h.repository.GetAllTasks()
+func (h *GetTasksHandler) Handle(ctx context.Context) (GetTasksOutput, error) {
+	tasks, err := h.repository.GetAllTasks(ctx)
 	if err != nil {
 		return GetTasksOutput{}, fmt.Errorf("failed to retrieve tasks: %w", err)
 	}
+	logging.FromContext(ctx).Debug("Tasks fetched", slog.Int("count", len(tasks)))
 
 	return GetTasksOutput{
 		Tasks: tasks,
@@ -1,67 +1,134 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/health"
+	"github.com/sumup/dependency-injection-go/internal/httpserver"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+	"github.com/sumup/dependency-injection-go/internal/ratelimit"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
 )
 
 func main() {
-	port := ":8080"
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Log structured records; the global log package writes through the same logger
+	logger := logging.New(cfg.Log, os.Stdout)
+	slog.SetDefault(logger)
+
+	// Collect request and repository metrics for every route
+	appMetrics := metrics.New()
+
+	// The repository checks its own database
+	repository, err := NewRepository(appMetrics)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	checker := health.NewChecker(cfg.Health.CheckTimeout,
+		health.Check{Name: "database", Run: repository.Ping},
+		health.Check{Name: "migrations", Run: repository.CheckMigrations},
+	)
+	if cfg.Health.ReplicaDSN != "" {
+		checker.Register(health.ReplicaLagCheck(cfg.Health.ReplicaDSN, cfg.Health.MaxReplicaLag))
+	}
 
 	// Register single generic handler for all routes
-	server := &http.Server{
-		Addr: port,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			// Handle preflight requests
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			// Log incoming request
-			log.Printf("Received %s request to %s", r.Method, r.URL.Path)
-
-			// Handle different paths and methods programmatically
-			switch {
-			case r.URL.Path == "/health" && r.Method == "GET":
-				handleHealth(w)
-			case r.URL.Path == "/tasks" && r.Method == "GET":
-				handleGetTasks(w)
-			case r.URL.Path == "/tasks" && r.Method == "POST":
-				handleCreateTask(w, r)
-			case r.Method == "POST" && len(r.URL.Path) > 7 && r.URL.Path[:7] == "/tasks/":
-				handleUpdateTaskStatus(w, r)
-			default:
-				// Handle 404 Not Found
-				w.WriteHeader(http.StatusNotFound)
-				fmt.Fprintf(w, "404 - Path %s not found\n", r.URL.Path)
-			}
-		}),
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Set CORS headers
+		if origin, ok := cfg.CORS.AllowOrigin(r.Header.Get("Origin")); ok {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+requestid.Header)
+		w.Header().Set("Access-Control-Expose-Headers", requestid.Header)
+
+		// Handle preflight requests
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Handle different paths and methods programmatically
+		switch {
+		case r.URL.Path == "/metrics" && r.Method == "GET":
+			appMetrics.Handler().ServeHTTP(w, r)
+		case r.URL.Path == "/livez" && r.Method == "GET":
+			health.LiveHandler().ServeHTTP(w, r)
+		case (r.URL.Path == "/readyz" || r.URL.Path == "/health") && r.Method == "GET":
+			checker.ReadyHandler().ServeHTTP(w, r)
+		case r.URL.Path == "/tasks" && r.Method == "GET":
+			handleGetTasks(w, r, appMetrics)
+		case r.URL.Path == "/tasks" && r.Method == "POST":
+			handleCreateTask(w, r, appMetrics)
+		case r.Method == "POST" && len(r.URL.Path) > 7 && r.URL.Path[:7] == "/tasks/":
+			handleUpdateTaskStatus(w, r, appMetrics)
+		default:
+			// Handle 404 Not Found
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "404 - Path %s not found\n", r.URL.Path)
+		}
+	})
+
+	// Limit clients before their requests open database connections. Buckets are kept in memory
+	// unless instances share them in Postgres, which is the only use of a pool in this server.
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		pool, err := database.NewPool(context.Background(), cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to create rate limit pool: %v", err)
+		}
+		defer pool.Close()
+		store = ratelimit.NewPostgresStore(pool)
 	}
+	limiter := ratelimit.NewLimiter(cfg.RateLimit, store)
+
+	// Identify every request and attach a request-scoped logger before anything else runs
+	logged := logging.Middleware(logger, routeOf, appMetrics.Middleware(routeOf, ratelimit.Middleware(limiter, routeOf, ratelimit.ClientIP, handler)))
+	server := httpserver.New(cfg.HTTP, requestid.Middleware(logged))
+
+	// Stop accepting requests on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Start the server
-	fmt.Printf("Server starting on port %s\n", port)
-	if err := server.ListenAndServe(); err != nil {
+	logger.Info("Server starting", slog.String("addr", cfg.HTTP.Addr))
+	if err := server.Run(ctx); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
 
-// handleHealth processes the health check request
-func handleHealth(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+// routeOf maps a request to its route pattern so metric labels stay bounded
+func routeOf(r *http.Request) string {
+	switch {
+	case r.URL.Path == "/livez" || r.URL.Path == "/readyz" || r.URL.Path == "/health" ||
+		r.URL.Path == "/metrics" || r.URL.Path == "/tasks":
+		return r.URL.Path
+	case len(r.URL.Path) > 7 && r.URL.Path[:7] == "/tasks/":
+		return "/tasks/:id"
+	default:
+		return ""
+	}
 }
 
 // handleCreateTask handles POST requests to create new tasks
-func handleCreateTask(w http.ResponseWriter, r *http.Request) {
+func handleCreateTask(w http.ResponseWriter, r *http.Request, appMetrics *metrics.Metrics) {
 	// Parse the request body
 	var input CreateTaskInput
 	decoder := json.NewDecoder(r.Body)
@@ -71,16 +138,20 @@ func handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handler, err := NewCreateTaskHandler()
+	handler, err := NewCreateTaskHandler(appMetrics)
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to instantiate handler", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error instantiating handler: %v", err)
+		return
 	}
 
-	output, err := handler.Handle(input)
+	output, err := handler.Handle(r.Context(), input)
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to create task", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error creating task: %v", err)
+		return
 	}
 
 	// Return success response
@@ -90,7 +161,7 @@ func handleCreateTask(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleUpdateTaskStatus handles POST requests to update the status of a task
-func handleUpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
+func handleUpdateTaskStatus(w http.ResponseWriter, r *http.Request, appMetrics *metrics.Metrics) {
 	// Extract task ID from URL
 	taskIDStr := r.URL.Path[7:] // Remove "/tasks/" prefix
 	taskID, err := strconv.Atoi(taskIDStr)
@@ -110,16 +181,25 @@ func handleUpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	input.TaskID = taskID
 
-	handler, err := NewUpdateTaskStatusHandler()
+	handler, err := NewUpdateTaskStatusHandler(appMetrics)
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to instantiate handler", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error instantiating handler: %v", err)
+		return
 	}
 
-	output, err := handler.Handle(input)
+	output, err := handler.Handle(r.Context(), input)
+	if errors.Is(err, ErrInvalidTransition) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, "Error updating task: %v", err)
+		return
+	}
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to update task", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error updating task: %v", err)
+		return
 	}
 
 	// Return success response
@@ -129,16 +209,19 @@ func handleUpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleGetTasks handles GET requests to retrieve all tasks
-func handleGetTasks(w http.ResponseWriter) {
+func handleGetTasks(w http.ResponseWriter, r *http.Request, appMetrics *metrics.Metrics) {
 
-	handler, err := NewGetTasksHandler()
+	handler, err := NewGetTasksHandler(appMetrics)
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to instantiate handler", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error instantiating handler: %v", err)
+		return
 	}
 
-	output, err := handler.Handle()
+	output, err := handler.Handle(r.Context())
 	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to fetch tasks", slog.Any("error", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error fetching tasks: %v", err)
 		return
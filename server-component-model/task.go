@@ -1,5 +1,7 @@
 package main
 
+import "errors"
+
 // Task represents a task in our system
 type Task struct {
 	ID          int        `json:"id"`
@@ -15,3 +17,14 @@ const (
 	TaskStatusPending   TaskStatus = "pending"
 	TaskStatusCompleted TaskStatus = "completed"
 )
+
+// ErrInvalidTransition is returned when the current status of a task does not allow the new one
+var ErrInvalidTransition = errors.New("invalid task status transition")
+
+// previousStatuses lists the statuses a task may have before this server moves it to each status.
+// The dependency injection server shares the database and sets further statuses: blocked and
+// cancelled tasks cannot be completed, while any task may be reopened.
+var previousStatuses = map[TaskStatus][]string{
+	TaskStatusPending:   {"pending", "in_progress", "blocked", "completed", "cancelled"},
+	TaskStatusCompleted: {"pending", "in_progress", "completed"},
+}
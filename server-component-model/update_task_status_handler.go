@@ -1,13 +1,20 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
 
 type UpdateTaskStatusHandler struct {
 	repository *Repository
 }
 
-func NewUpdateTaskStatusHandler() (*UpdateTaskStatusHandler, error) {
-	repository, err := NewRepository()
+func NewUpdateTaskStatusHandler(metrics *metrics.Metrics) (*UpdateTaskStatusHandler, error) {
+	repository, err := NewRepository(metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize repository: %w", err)
 	}
@@ -26,7 +33,7 @@ type UpdateTaskStatusOutput struct {
 	Task Task `json:"task"`
 }
 
-func (h *UpdateTaskStatusHandler) Handle(input UpdateTaskStatusInput) (UpdateTaskStatusOutput, error) {
+func (h *UpdateTaskStatusHandler) Handle(ctx context.Context, input UpdateTaskStatusInput) (UpdateTaskStatusOutput, error) {
 
 	taskStatus := TaskStatus(input.Status)
 
@@ -36,10 +43,11 @@ func (h *UpdateTaskStatusHandler) Handle(input UpdateTaskStatusInput) (UpdateTas
 	}
 
 	// Update the task status using repository
-	updatedTask, err := h.repository.UpdateTaskStatus(input.TaskID, taskStatus)
+	updatedTask, err := h.repository.UpdateTaskStatus(ctx, input.TaskID, taskStatus)
 	if err != nil {
 		return UpdateTaskStatusOutput{}, fmt.Errorf("failed to update task: %w", err)
 	}
+	logging.FromContext(ctx).Info("Task status updated", slog.Int("task_id", updatedTask.ID), slog.String("status", string(updatedTask.Status)))
 
 	return UpdateTaskStatusOutput{
 		Task: updatedTask,
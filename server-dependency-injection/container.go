@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/changefeed"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/events"
+	"github.com/sumup/dependency-injection-go/internal/health"
+	"github.com/sumup/dependency-injection-go/internal/idempotency"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+	"github.com/sumup/dependency-injection-go/internal/ratelimit"
+	"github.com/sumup/dependency-injection-go/internal/stream"
+	"github.com/sumup/dependency-injection-go/internal/tracing"
+	"github.com/sumup/dependency-injection-go/internal/webhooks"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/handlers"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/dig"
+)
+
+// containerKey is the gin context key holding the request's container
+const containerKey = "container"
+
+// RegisterServices registers every dependency of the application in the container
+func RegisterServices(container *dig.Container) error {
+	providers := []any{
+		config.Load,
+		newLogger,
+		newTracerProvider,
+		asTracerProvider,
+		newPool,
+		newTxManager,
+		newVerifier,
+		newAuthorizer,
+		newRateLimiter,
+		newIdempotencyStore,
+		newBus,
+		newPublisher,
+		newDispatcher,
+		newWebhookSender,
+		newBroker,
+		newChangeFeed,
+		newTaskCache,
+		newMetrics,
+		newHealthChecker,
+		newDatabaseHealthChecks,
+		newReplicaHealthCheck,
+		repository.NewRepository,
+		repository.NewUserRepository,
+		repository.NewAPIKeyRepository,
+		repository.NewTaskEventRepository,
+		repository.NewWebhookRepository,
+		auth.NewAPIKeyAuthenticator,
+		handlers.NewCreateTaskHandler,
+		handlers.NewGetTasksHandler,
+		handlers.NewUpdateTaskStatusHandler,
+		handlers.NewGetTaskHistoryHandler,
+		handlers.NewGetTaskEventsHandler,
+		handlers.NewStreamTasksHandler,
+		handlers.NewCreateWebhookHandler,
+		handlers.NewGetWebhooksHandler,
+		handlers.NewDeleteWebhookHandler,
+		handlers.NewGetWebhookDeliveriesHandler,
+		handlers.NewRedeliverWebhookHandler,
+	}
+
+	for _, provider := range providers {
+		if err := container.Provide(provider); err != nil {
+			return fmt.Errorf("failed to register %T: %w", provider, err)
+		}
+	}
+
+	// Decorators wrap dependencies that were already registered
+	decorators := []any{
+		decorateRepository,
+		authz.NewAuditingAuthorizer,
+	}
+
+	for _, decorator := range decorators {
+		if err := container.Decorate(decorator); err != nil {
+			return fmt.Errorf("failed to register decorator %T: %w", decorator, err)
+		}
+	}
+
+	return nil
+}
+
+func newLogger(cfg config.Config) *slog.Logger {
+	return logging.New(cfg.Log, os.Stdout)
+}
+
+func newTracerProvider(cfg config.Config) (*sdktrace.TracerProvider, error) {
+	return tracing.NewTracerProvider(context.Background(), cfg.Tracing)
+}
+
+// asTracerProvider lets dependencies create spans without depending on the SDK
+func asTracerProvider(provider *sdktrace.TracerProvider) trace.TracerProvider {
+	return provider
+}
+
+func newPool(cfg config.Config, tracerProvider trace.TracerProvider) (*pgxpool.Pool, error) {
+	return database.NewPool(context.Background(), cfg.Database,
+		database.WithQueryTracer(tracing.NewQueryTracer(tracerProvider)),
+		// Queries carry the request ID as a comment, so their text is unique per
+		// request and would only churn the prepared statement cache
+		database.WithQueryExecMode(pgx.QueryExecModeExec),
+	)
+}
+
+// newTxManager retries units of work that conflict with concurrent writes under repeatable read
+func newTxManager(pool *pgxpool.Pool) database.TxManager {
+	return database.NewTxManager(pool, database.WithIsolationLevel(pgx.RepeatableRead))
+}
+
+// newVerifier returns nil when authentication is disabled
+func newVerifier(cfg config.Config) (*auth.Verifier, error) {
+	if !cfg.Auth.Enabled {
+		return nil, nil
+	}
+	return auth.NewVerifier(cfg.Auth)
+}
+
+func newAuthorizer(cfg config.Config) (authz.Authorizer, error) {
+	return authz.NewPolicyAuthorizer(cfg.Authz)
+}
+
+// newRateLimiter keeps buckets in memory, or in Postgres to share them between instances
+func newRateLimiter(cfg config.Config, pool *pgxpool.Pool) *ratelimit.Limiter {
+	return ratelimit.NewLimiter(cfg.RateLimit, ratelimit.NewStore(cfg.RateLimit, pool))
+}
+
+func newIdempotencyStore(cfg config.Config, pool *pgxpool.Pool) idempotency.Store {
+	return idempotency.NewPostgresStore(pool, cfg.Idempotency)
+}
+
+// newBus creates the event bus with the subscribers of every domain event
+func newBus(logger *slog.Logger, pool *pgxpool.Pool) *events.Bus {
+	bus := events.NewBus()
+
+	// Log task events so their delivery can be followed
+	logEvent := func(ctx context.Context, event events.Event) error {
+		logging.FromContext(ctx).Info("Domain event delivered", slog.String("payload", string(event.Payload)))
+		return nil
+	}
+	bus.Subscribe(handlers.EventTaskCreated, "log", logEvent)
+	bus.Subscribe(handlers.EventTaskStatusChanged, "log", logEvent)
+
+	// Queue deliveries to the webhooks of the task's workspace
+	queueWebhooks := webhooks.Subscriber(pool, taskEventWorkspace)
+	for _, eventType := range handlers.DomainEvents {
+		bus.Subscribe(eventType, "webhooks", queueWebhooks)
+	}
+
+	return bus
+}
+
+func newPublisher() events.Publisher {
+	return events.NewOutboxPublisher()
+}
+
+func newDispatcher(cfg config.Config, pool *pgxpool.Pool, bus *events.Bus, logger *slog.Logger) *events.Dispatcher {
+	return events.NewDispatcher(pool, bus, cfg.Outbox, logger)
+}
+
+// taskEventWorkspace reads the workspace of the task every domain event carries
+func taskEventWorkspace(event events.Event) (int, error) {
+	var payload struct {
+		Task repository.Task `json:"task"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return 0, err
+	}
+	return payload.Task.WorkspaceID, nil
+}
+
+func newBroker(cfg config.Config) *stream.Broker {
+	return stream.NewBroker(cfg.Stream.HistorySize, cfg.Stream.BufferSize)
+}
+
+// newChangeFeed listens for the writes of every instance and passes them to the local subscribers
+func newChangeFeed(cfg config.Config, logger *slog.Logger, streams *handlers.StreamTasksHandler, taskCache *repository.TaskCache) *changefeed.Listener {
+	connect := func(ctx context.Context) (*pgx.Conn, error) {
+		return database.Connect(ctx, cfg.Database)
+	}
+	listener := changefeed.NewListener(connect, repository.TaskChangesChannel, cfg.ChangeFeed, logger)
+
+	// Invalidate first, so that the stream loads the changed task
+	if taskCache != nil && cfg.Cache.InvalidateOnChanges {
+		listener.Subscribe(func(ctx context.Context, payload string) {
+			change, err := repository.ParseTaskChange(payload)
+			if err != nil {
+				taskCache.Clear()
+				return
+			}
+			taskCache.InvalidateWorkspace(change.WorkspaceID)
+		})
+		listener.OnReset(func(ctx context.Context) { taskCache.Clear() })
+	}
+
+	// Push changes to the clients of GET /tasks/stream
+	listener.Subscribe(func(ctx context.Context, payload string) {
+		change, err := repository.ParseTaskChange(payload)
+		if err == nil {
+			err = streams.PublishChange(ctx, change)
+		}
+		if err != nil {
+			logger.Error("Failed to stream task change", slog.Any("error", err))
+		}
+	})
+	listener.OnReset(func(ctx context.Context) { streams.Reset() })
+
+	return listener
+}
+
+// newTaskCache returns nil when caching is disabled
+func newTaskCache(cfg config.Config, m *metrics.Metrics) *repository.TaskCache {
+	if !cfg.Cache.Enabled {
+		return nil
+	}
+	return repository.NewTaskCache(cfg.Cache, m)
+}
+
+// decorateRepository instruments the repository and serves repeated reads from the cache, when
+// enabled. A type can only be decorated once per container, so the decorators are stacked here.
+func decorateRepository(repo repository.IRepository, m *metrics.Metrics, taskCache *repository.TaskCache) repository.IRepository {
+	repo = repository.NewInstrumentedRepository(repo, m)
+	if taskCache == nil {
+		return repo
+	}
+	// Cache hits are not repository calls, so the cache wraps the instrumented repository
+	return repository.NewCachingRepository(repo, taskCache)
+}
+
+func newWebhookSender(cfg config.Config, pool *pgxpool.Pool, logger *slog.Logger) *webhooks.Sender {
+	return webhooks.NewSender(pool, cfg.Webhooks, logger)
+}
+
+func newMetrics(pool *pgxpool.Pool) (*metrics.Metrics, error) {
+	m := metrics.New()
+	if err := m.Register(metrics.NewPoolCollector(pool)); err != nil {
+		return nil, fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+	return m, nil
+}
+
+// HealthChecks lets any provider contribute readiness checks by returning it
+type HealthChecks struct {
+	dig.Out
+
+	Checks []health.Check `group:"health_checks,flatten"`
+}
+
+type healthCheckerParams struct {
+	dig.In
+
+	Config config.Config
+	Checks []health.Check `group:"health_checks"`
+}
+
+func newHealthChecker(params healthCheckerParams) *health.Checker {
+	return health.NewChecker(params.Config.Health.CheckTimeout, params.Checks...)
+}
+
+func newDatabaseHealthChecks(cfg config.Config, pool *pgxpool.Pool) HealthChecks {
+	checks := []health.Check{
+		health.PingCheck("database", pool),
+		health.MigrationCheck(pool),
+	}
+	// Anonymous callers all share the default workspace, so no other may exist
+	if !cfg.Auth.Enabled {
+		checks = append(checks, health.SingleWorkspaceCheck(pool))
+	}
+	return HealthChecks{Checks: checks}
+}
+
+func newReplicaHealthCheck(cfg config.Config) HealthChecks {
+	// The replica check is optional
+	if cfg.Health.ReplicaDSN == "" {
+		return HealthChecks{}
+	}
+
+	return HealthChecks{
+		Checks: []health.Check{
+			health.ReplicaLagCheck(cfg.Health.ReplicaDSN, cfg.Health.MaxReplicaLag),
+		},
+	}
+}
+
+// ContainerMiddleware makes the container available to the handlers of every request
+func ContainerMiddleware(container *dig.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(containerKey, container)
+		c.Next()
+	}
+}
+
+// ResolveFromGin resolves a dependency from the container attached to the request
+func ResolveFromGin[T any](c *gin.Context) (T, error) {
+	var dependency T
+
+	container, ok := c.MustGet(containerKey).(*dig.Container)
+	if !ok {
+		return dependency, fmt.Errorf("no container attached to the request")
+	}
+
+	err := container.Invoke(func(resolved T) {
+		dependency = resolved
+	})
+	return dependency, err
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/tenant"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+)
+
+var (
+	// ErrWorkspaceRequired is returned when a caller belonging to several workspaces did not select one
+	ErrWorkspaceRequired = errors.New("workspace required")
+	// ErrWorkspaceForbidden is returned when the caller selected a workspace they are not a member of
+	ErrWorkspaceForbidden = errors.New("workspace forbidden")
+)
+
+// callerScope returns the scope of the authenticated caller, restricted to their own
+// tasks. Handlers lift the restriction when the authorizer allows it. Without
+// authentication every task of the default workspace is visible.
+//
+// The workspace comes from the token when it is pinned to one, otherwise from the
+// X-Workspace-ID header, otherwise from the caller's only membership. Anonymous callers
+// cannot select a workspace: the header is not proof of membership.
+func callerScope(ctx context.Context, users repository.IUserRepository) (repository.Scope, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return repository.Scope{AllOwners: true, WorkspaceID: tenant.DefaultWorkspaceID}, nil
+	}
+
+	requested, _ := tenant.RequestedWorkspace(ctx)
+
+	user, err := users.GetUserByUsername(ctx, principal.Subject)
+	if err != nil {
+		return repository.Scope{}, fmt.Errorf("failed to identify caller: %w", err)
+	}
+
+	workspaceID, err := callerWorkspace(ctx, users, principal, user, requested)
+	if err != nil {
+		return repository.Scope{}, err
+	}
+
+	return repository.Scope{
+		OwnerID:     user.ID,
+		WorkspaceID: workspaceID,
+	}, nil
+}
+
+// callerActor returns the subject recorded as the actor of changes, or nil for anonymous callers
+func callerActor(ctx context.Context) *string {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &principal.Subject
+}
+
+// allows reports whether the caller may perform the action. Without authentication every action is allowed.
+func allows(ctx context.Context, authorizer authz.Authorizer, action authz.Action, resource authz.Resource) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return !ok || authorizer.Allows(principal, action, resource)
+}
+
+// authorize is like allows but returns authz.ErrForbidden, and audits the denial
+func authorize(ctx context.Context, authorizer authz.Authorizer, action authz.Action, resource authz.Resource) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return authorizer.Authorize(ctx, principal, action, resource)
+}
+
+func callerWorkspace(ctx context.Context, users repository.IUserRepository, principal auth.Principal, user repository.User, requested int) (int, error) {
+	if principal.WorkspaceID != 0 {
+		if requested != 0 && requested != principal.WorkspaceID {
+			return 0, fmt.Errorf("%w: token is pinned to workspace %d", ErrWorkspaceForbidden, principal.WorkspaceID)
+		}
+		requested = principal.WorkspaceID
+	}
+
+	memberships, err := users.GetWorkspaceIDs(ctx, user.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to identify workspace: %w", err)
+	}
+
+	if requested == 0 {
+		if len(memberships) != 1 {
+			return 0, fmt.Errorf("%w: set the %s header", ErrWorkspaceRequired, tenant.Header)
+		}
+		return memberships[0], nil
+	}
+
+	// Admins may operate on any workspace
+	if !principal.HasRole(auth.RoleAdmin) && !slices.Contains(memberships, requested) {
+		return 0, fmt.Errorf("%w: %s is not a member of workspace %d", ErrWorkspaceForbidden, user.Username, requested)
+	}
+	return requested, nil
+}
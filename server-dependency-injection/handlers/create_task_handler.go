@@ -1,18 +1,37 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/events"
+	"github.com/sumup/dependency-injection-go/internal/logging"
 	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CreateTaskHandler struct {
+	txManager  database.TxManager
 	repository repository.IRepository
+	history    repository.ITaskEventRepository
+	publisher  events.Publisher
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
 }
 
-func NewCreateTaskHandler(repository repository.IRepository) *CreateTaskHandler {
+func NewCreateTaskHandler(txManager database.TxManager, repository repository.IRepository, history repository.ITaskEventRepository, publisher events.Publisher, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *CreateTaskHandler {
 	handler := CreateTaskHandler{
+		txManager:  txManager,
 		repository: repository,
+		history:    history,
+		publisher:  publisher,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
 	}
 	return &handler
 }
@@ -26,7 +45,19 @@ type CreateTaskOutput struct {
 	Task repository.Task `json:"task"`
 }
 
-func (h *CreateTaskHandler) Handle(input CreateTaskInput) (CreateTaskOutput, error) {
+func (h *CreateTaskHandler) Handle(ctx context.Context, input CreateTaskInput) (output CreateTaskOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "CreateTaskHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return CreateTaskOutput{}, err
+	}
+
+	// New tasks belong to their creator
+	if err := authorize(ctx, h.authorizer, authz.ActionCreateTask, authz.Resource{Owned: true}); err != nil {
+		return CreateTaskOutput{}, err
+	}
 
 	// Validate required fields
 	if input.Title == "" {
@@ -39,11 +70,35 @@ func (h *CreateTaskHandler) Handle(input CreateTaskInput) (CreateTaskOutput, err
 		Status:      repository.TaskStatusPending,
 	}
 
-	// Create the task using repository
-	createdTask, err := h.repository.CreateTask(task)
+	// Create the task and record it in its history together
+	var createdTask repository.Task
+	err = h.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		createdTask, err = h.repository.CreateTask(ctx, scope, task)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+
+		_, err = h.history.RecordTaskEvent(ctx, scope, repository.TaskEvent{
+			TaskID: createdTask.ID,
+			Type:   repository.TaskEventCreated,
+			Actor:  callerActor(ctx),
+			After: map[string]any{
+				"title":       createdTask.Title,
+				"description": createdTask.Description,
+				"status":      createdTask.Status,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return h.publisher.Publish(ctx, EventTaskCreated, TaskCreated{Task: createdTask, Actor: callerActor(ctx)})
+	})
 	if err != nil {
-		return CreateTaskOutput{}, fmt.Errorf("failed to create task: %w", err)
+		return CreateTaskOutput{}, err
 	}
+	logging.FromContext(ctx).Info("Task created", slog.Int("task_id", createdTask.ID))
 
 	return CreateTaskOutput{
 		Task: createdTask,
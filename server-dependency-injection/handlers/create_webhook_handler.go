@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInvalidWebhook is returned when a webhook has an unusable URL or subscribes to unknown events
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type CreateWebhookHandler struct {
+	webhooks   repository.IWebhookRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewCreateWebhookHandler(webhooks repository.IWebhookRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *CreateWebhookHandler {
+	handler := CreateWebhookHandler{
+		webhooks:   webhooks,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type CreateWebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Secret is generated when empty
+	Secret string `json:"secret"`
+}
+
+type CreateWebhookOutput struct {
+	Webhook repository.Webhook `json:"webhook"`
+}
+
+func (h *CreateWebhookHandler) Handle(ctx context.Context, input CreateWebhookInput) (output CreateWebhookOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "CreateWebhookHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return CreateWebhookOutput{}, err
+	}
+
+	// Webhooks receive the events of every task in the workspace
+	if err := authorize(ctx, h.authorizer, authz.ActionManageWebhooks, authz.Resource{}); err != nil {
+		return CreateWebhookOutput{}, err
+	}
+
+	if err := validateWebhook(input); err != nil {
+		return CreateWebhookOutput{}, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			return CreateWebhookOutput{}, err
+		}
+	}
+
+	webhook, err := h.webhooks.CreateWebhook(ctx, scope, repository.Webhook{
+		URL:        input.URL,
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(input.EventTypes))),
+		Secret:     secret,
+	})
+	if err != nil {
+		return CreateWebhookOutput{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	logging.FromContext(ctx).Info("Webhook created", slog.Int("webhook_id", webhook.ID))
+
+	return CreateWebhookOutput{
+		Webhook: webhook,
+	}, nil
+}
+
+// validateWebhook checks the form of the endpoint. Where it points is checked by the sender on
+// every connection, which refuses private addresses unless webhooks.allowPrivateAddresses is set.
+func validateWebhook(input CreateWebhookInput) error {
+	endpoint, err := url.Parse(input.URL)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+
+	if len(input.EventTypes) == 0 {
+		return fmt.Errorf("%w: eventTypes is required", ErrInvalidWebhook)
+	}
+	for _, eventType := range input.EventTypes {
+		if !slices.Contains(DomainEvents, eventType) {
+			return fmt.Errorf("%w: unknown event type %q, expected one of %v", ErrInvalidWebhook, eventType, DomainEvents)
+		}
+	}
+
+	// Short secrets would make signatures guessable
+	if input.Secret != "" && len(input.Secret) < 16 {
+		return fmt.Errorf("%w: secret must be at least 16 characters", ErrInvalidWebhook)
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
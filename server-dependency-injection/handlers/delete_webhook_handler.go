@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DeleteWebhookHandler struct {
+	webhooks   repository.IWebhookRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewDeleteWebhookHandler(webhooks repository.IWebhookRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *DeleteWebhookHandler {
+	handler := DeleteWebhookHandler{
+		webhooks:   webhooks,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type DeleteWebhookInput struct {
+	WebhookID int `uri:"id"`
+}
+
+// Handle deletes the webhook together with its delivery log; pending deliveries are not sent
+func (h *DeleteWebhookHandler) Handle(ctx context.Context, input DeleteWebhookInput) (err error) {
+	ctx, span := h.tracer.Start(ctx, "DeleteWebhookHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return err
+	}
+
+	if err := authorize(ctx, h.authorizer, authz.ActionManageWebhooks, authz.Resource{}); err != nil {
+		return err
+	}
+
+	if err := h.webhooks.DeleteWebhook(ctx, scope, input.WebhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	logging.FromContext(ctx).Info("Webhook deleted", slog.Int("webhook_id", input.WebhookID))
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import "github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+
+// Domain events published through the outbox when tasks change
+const (
+	EventTaskCreated       = "TaskCreated"
+	EventTaskStatusChanged = "TaskStatusChanged"
+)
+
+// DomainEvents lists the events webhooks can subscribe to
+var DomainEvents = []string{EventTaskCreated, EventTaskStatusChanged}
+
+// TaskCreated is the payload of EventTaskCreated
+type TaskCreated struct {
+	Task  repository.Task `json:"task"`
+	Actor *string         `json:"actor"`
+}
+
+// TaskStatusChanged is the payload of EventTaskStatusChanged
+type TaskStatusChanged struct {
+	Task           repository.Task       `json:"task"`
+	PreviousStatus repository.TaskStatus `json:"previousStatus"`
+	Actor          *string               `json:"actor"`
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultEventLimit is the page size of the audit feed unless the caller asks for another
+	defaultEventLimit = 100
+	// maxEventLimit bounds the page size of the audit feed
+	maxEventLimit = 1000
+)
+
+type GetTaskEventsHandler struct {
+	history    repository.ITaskEventRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewGetTaskEventsHandler(history repository.ITaskEventRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *GetTaskEventsHandler {
+	handler := GetTaskEventsHandler{
+		history:    history,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+// GetTaskEventsInput filters the audit feed; every filter is optional
+type GetTaskEventsInput struct {
+	TaskID   int        `form:"taskId" binding:"gte=0"`
+	Actor    string     `form:"actor"`
+	Type     string     `form:"type"`
+	Since    *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until    *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	BeforeID int64      `form:"beforeId" binding:"gte=0"`
+	Limit    int        `form:"limit" binding:"gte=0"`
+}
+
+type GetTaskEventsOutput struct {
+	Events []repository.TaskEvent `json:"events"`
+}
+
+func (h *GetTaskEventsHandler) Handle(ctx context.Context, input GetTaskEventsInput) (output GetTaskEventsOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "GetTaskEventsHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return GetTaskEventsOutput{}, err
+	}
+
+	// The audit feed spans every task of the workspace
+	if err := authorize(ctx, h.authorizer, authz.ActionAuditTasks, authz.Resource{}); err != nil {
+		return GetTaskEventsOutput{}, err
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultEventLimit
+	}
+	limit = min(limit, maxEventLimit)
+
+	events, err := h.history.GetTaskEvents(ctx, scope, repository.TaskEventFilter{
+		TaskID:   input.TaskID,
+		Actor:    input.Actor,
+		Type:     repository.TaskEventType(input.Type),
+		Since:    input.Since,
+		Until:    input.Until,
+		BeforeID: input.BeforeID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return GetTaskEventsOutput{}, fmt.Errorf("failed to retrieve task events: %w", err)
+	}
+
+	return GetTaskEventsOutput{
+		Events: events,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GetTaskHistoryHandler struct {
+	repository repository.IRepository
+	history    repository.ITaskEventRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewGetTaskHistoryHandler(repository repository.IRepository, history repository.ITaskEventRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *GetTaskHistoryHandler {
+	handler := GetTaskHistoryHandler{
+		repository: repository,
+		history:    history,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type GetTaskHistoryInput struct {
+	TaskID int `uri:"id"`
+}
+
+type GetTaskHistoryOutput struct {
+	Events []repository.TaskEvent `json:"events"`
+}
+
+func (h *GetTaskHistoryHandler) Handle(ctx context.Context, input GetTaskHistoryInput) (output GetTaskHistoryOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "GetTaskHistoryHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return GetTaskHistoryOutput{}, err
+	}
+
+	// The history of a task is visible to whoever may see the task
+	if allows(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{}) {
+		scope.AllOwners = true
+	} else if err := authorize(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{Owned: true}); err != nil {
+		return GetTaskHistoryOutput{}, err
+	}
+
+	if _, err := h.repository.GetTaskById(ctx, scope, input.TaskID); err != nil {
+		return GetTaskHistoryOutput{}, fmt.Errorf("failed to retrieve task history: %w", err)
+	}
+
+	events, err := h.history.GetTaskHistory(ctx, scope, input.TaskID)
+	if err != nil {
+		return GetTaskHistoryOutput{}, fmt.Errorf("failed to retrieve task history: %w", err)
+	}
+
+	return GetTaskHistoryOutput{
+		Events: events,
+	}, nil
+}
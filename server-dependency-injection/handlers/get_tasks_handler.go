@@ -1,31 +1,58 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/logging"
 	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type GetTasksHandler struct {
 	repository repository.IRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
 }
 
 type GetTasksOutput struct {
 	Tasks []repository.Task `json:"tasks"`
 }
 
-func NewGetTasksHandler(repository repository.IRepository) *GetTasksHandler {
+func NewGetTasksHandler(repository repository.IRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *GetTasksHandler {
 	handler := GetTasksHandler{
 		repository: repository,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
 	}
 	return &handler
 }
 
-func (h *GetTasksHandler) Handle() (GetTasksOutput, error) {
-	tasks, err := h.repository.GetAllTasks()
+func (h *GetTasksHandler) Handle(ctx context.Context) (output GetTasksOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "GetTasksHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return GetTasksOutput{}, err
+	}
+
+	// Callers allowed to list every task see the whole workspace, others only their own tasks
+	if allows(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{}) {
+		scope.AllOwners = true
+	} else if err := authorize(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{Owned: true}); err != nil {
+		return GetTasksOutput{}, err
+	}
+
+	tasks, err := h.repository.GetAllTasks(ctx, scope)
 	if err != nil {
 		return GetTasksOutput{}, fmt.Errorf("failed to retrieve tasks: %w", err)
 	}
+	logging.FromContext(ctx).Debug("Tasks fetched", slog.Int("count", len(tasks)))
 
 	return GetTasksOutput{
 		Tasks: tasks,
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GetWebhookDeliveriesHandler struct {
+	webhooks   repository.IWebhookRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewGetWebhookDeliveriesHandler(webhooks repository.IWebhookRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *GetWebhookDeliveriesHandler {
+	handler := GetWebhookDeliveriesHandler{
+		webhooks:   webhooks,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type GetWebhookDeliveriesInput struct {
+	WebhookID int `uri:"id"`
+	Limit     int `form:"limit" binding:"gte=0"`
+}
+
+type GetWebhookDeliveriesOutput struct {
+	Deliveries []repository.WebhookDelivery `json:"deliveries"`
+}
+
+func (h *GetWebhookDeliveriesHandler) Handle(ctx context.Context, input GetWebhookDeliveriesInput) (output GetWebhookDeliveriesOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "GetWebhookDeliveriesHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return GetWebhookDeliveriesOutput{}, err
+	}
+
+	if err := authorize(ctx, h.authorizer, authz.ActionManageWebhooks, authz.Resource{}); err != nil {
+		return GetWebhookDeliveriesOutput{}, err
+	}
+
+	// The delivery log pages like the audit feed
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultEventLimit
+	}
+	limit = min(limit, maxEventLimit)
+
+	deliveries, err := h.webhooks.GetWebhookDeliveries(ctx, scope, input.WebhookID, limit)
+	if err != nil {
+		return GetWebhookDeliveriesOutput{}, fmt.Errorf("failed to retrieve webhook deliveries: %w", err)
+	}
+
+	return GetWebhookDeliveriesOutput{
+		Deliveries: deliveries,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GetWebhooksHandler struct {
+	webhooks   repository.IWebhookRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewGetWebhooksHandler(webhooks repository.IWebhookRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *GetWebhooksHandler {
+	handler := GetWebhooksHandler{
+		webhooks:   webhooks,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type GetWebhooksOutput struct {
+	Webhooks []repository.Webhook `json:"webhooks"`
+}
+
+func (h *GetWebhooksHandler) Handle(ctx context.Context) (output GetWebhooksOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "GetWebhooksHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return GetWebhooksOutput{}, err
+	}
+
+	if err := authorize(ctx, h.authorizer, authz.ActionManageWebhooks, authz.Resource{}); err != nil {
+		return GetWebhooksOutput{}, err
+	}
+
+	webhooks, err := h.webhooks.GetWebhooks(ctx, scope)
+	if err != nil {
+		return GetWebhooksOutput{}, fmt.Errorf("failed to retrieve webhooks: %w", err)
+	}
+
+	return GetWebhooksOutput{
+		Webhooks: webhooks,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RedeliverWebhookHandler struct {
+	webhooks   repository.IWebhookRepository
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewRedeliverWebhookHandler(webhooks repository.IWebhookRepository, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *RedeliverWebhookHandler {
+	handler := RedeliverWebhookHandler{
+		webhooks:   webhooks,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type RedeliverWebhookInput struct {
+	WebhookID  int   `uri:"id"`
+	DeliveryID int64 `uri:"deliveryId"`
+}
+
+type RedeliverWebhookOutput struct {
+	Delivery repository.WebhookDelivery `json:"delivery"`
+}
+
+// Handle schedules the delivery again; the sender attempts it with its next poll
+func (h *RedeliverWebhookHandler) Handle(ctx context.Context, input RedeliverWebhookInput) (output RedeliverWebhookOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "RedeliverWebhookHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return RedeliverWebhookOutput{}, err
+	}
+
+	if err := authorize(ctx, h.authorizer, authz.ActionManageWebhooks, authz.Resource{}); err != nil {
+		return RedeliverWebhookOutput{}, err
+	}
+
+	delivery, err := h.webhooks.RedeliverWebhookDelivery(ctx, scope, input.WebhookID, input.DeliveryID)
+	if err != nil {
+		return RedeliverWebhookOutput{}, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	logging.FromContext(ctx).Info("Webhook delivery scheduled for redelivery", slog.Int64("delivery_id", delivery.ID))
+
+	return RedeliverWebhookOutput{
+		Delivery: delivery,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/stream"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Events of the task stream
+const (
+	StreamEventCreated = "created"
+	StreamEventUpdated = "updated"
+	StreamEventDeleted = "deleted"
+	// StreamEventReset tells a resuming client that updates were missed and the tasks must be reloaded
+	StreamEventReset = "reset"
+)
+
+// streamEvents maps the writes of the change feed to stream events
+var streamEvents = map[repository.TaskChangeOp]string{
+	repository.TaskChangeInsert: StreamEventCreated,
+	repository.TaskChangeUpdate: StreamEventUpdated,
+	repository.TaskChangeDelete: StreamEventDeleted,
+}
+
+// TaskChange is the data of stream events. Deleted tasks only carry their ID, workspace and owner.
+type TaskChange struct {
+	Task repository.Task `json:"task"`
+}
+
+type StreamTasksHandler struct {
+	repository repository.IRepository
+	broker     *stream.Broker
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
+}
+
+func NewStreamTasksHandler(repository repository.IRepository, broker *stream.Broker, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *StreamTasksHandler {
+	handler := StreamTasksHandler{
+		repository: repository,
+		broker:     broker,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
+	}
+	return &handler
+}
+
+type StreamTasksInput struct {
+	// LastEventID resumes the stream after the last update the client received
+	LastEventID int64
+}
+
+type StreamTasksOutput struct {
+	Subscription *stream.Subscription
+	// Backlog holds the updates missed since LastEventID
+	Backlog []stream.Message
+	// Reset is true when missed updates are no longer retained
+	Reset bool
+}
+
+// Handle subscribes the caller to the updates of the tasks they may list. The caller must
+// Unsubscribe once the stream ends.
+func (h *StreamTasksHandler) Handle(ctx context.Context, input StreamTasksInput) (output StreamTasksOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "StreamTasksHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return StreamTasksOutput{}, err
+	}
+
+	// The stream shows the tasks GET /tasks would list
+	if allows(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{}) {
+		scope.AllOwners = true
+	} else if err := authorize(ctx, h.authorizer, authz.ActionListTasks, authz.Resource{Owned: true}); err != nil {
+		return StreamTasksOutput{}, err
+	}
+
+	visible := func(message stream.Message) bool {
+		change, ok := message.Data.(TaskChange)
+		if !ok || change.Task.WorkspaceID != scope.WorkspaceID {
+			return false
+		}
+		return scope.AllOwners || (change.Task.OwnerID != nil && *change.Task.OwnerID == scope.OwnerID)
+	}
+
+	subscription, backlog, complete := h.broker.Subscribe(input.LastEventID, visible)
+	return StreamTasksOutput{
+		Subscription: subscription,
+		Backlog:      backlog,
+		Reset:        !complete,
+	}, nil
+}
+
+// Unsubscribe ends the subscription returned by Handle
+func (h *StreamTasksHandler) Unsubscribe(subscription *stream.Subscription) {
+	h.broker.Unsubscribe(subscription)
+}
+
+// PublishChange pushes a change of the feed to the streams, identified by its change ID. The task
+// is loaded as it is now, so a change superseded by a later one sends the later state twice.
+func (h *StreamTasksHandler) PublishChange(ctx context.Context, change repository.TaskChange) error {
+	streamEvent, ok := streamEvents[change.Op]
+	if !ok {
+		return fmt.Errorf("unknown task change %q", change.Op)
+	}
+
+	task := repository.Task{ID: change.TaskID, WorkspaceID: change.WorkspaceID, OwnerID: change.OwnerID}
+	if change.Op != repository.TaskChangeDelete {
+		var err error
+		task, err = h.repository.GetTaskById(ctx, repository.Scope{AllOwners: true, WorkspaceID: change.WorkspaceID}, change.TaskID)
+		// A later change deletes the task and is streamed on its own
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load changed task: %w", err)
+		}
+	}
+
+	h.broker.Publish(stream.Message{ID: change.ChangeID, Event: streamEvent, Data: TaskChange{Task: task}})
+	return nil
+}
+
+// Reset makes every client reload, after changes may have been missed
+func (h *StreamTasksHandler) Reset() {
+	h.broker.Reset()
+}
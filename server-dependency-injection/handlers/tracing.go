@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans opened by the handlers
+const tracerName = "github.com/sumup/dependency-injection-go/server-dependency-injection/handlers"
+
+// endSpan records the handler's error, if any, and ends its span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
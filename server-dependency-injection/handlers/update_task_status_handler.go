@@ -1,18 +1,37 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/events"
+	"github.com/sumup/dependency-injection-go/internal/logging"
 	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UpdateTaskStatusHandler struct {
+	txManager  database.TxManager
 	repository repository.IRepository
+	history    repository.ITaskEventRepository
+	publisher  events.Publisher
+	users      repository.IUserRepository
+	authorizer authz.Authorizer
+	tracer     trace.Tracer
 }
 
-func NewUpdateTaskStatusHandler(repository repository.IRepository) *UpdateTaskStatusHandler {
+func NewUpdateTaskStatusHandler(txManager database.TxManager, repository repository.IRepository, history repository.ITaskEventRepository, publisher events.Publisher, users repository.IUserRepository, authorizer authz.Authorizer, tracerProvider trace.TracerProvider) *UpdateTaskStatusHandler {
 	handler := UpdateTaskStatusHandler{
+		txManager:  txManager,
 		repository: repository,
+		history:    history,
+		publisher:  publisher,
+		users:      users,
+		authorizer: authorizer,
+		tracer:     tracerProvider.Tracer(tracerName),
 	}
 	return &handler
 }
@@ -20,26 +39,73 @@ func NewUpdateTaskStatusHandler(repository repository.IRepository) *UpdateTaskSt
 type UpdateTaskStatusInput struct {
 	TaskID int    `uri:"id" json:"taskId"`
 	Status string `json:"status"`
+	// Version is the version the caller expects the task to have, or 0 to update any version
+	Version int `json:"-"`
 }
 
 type UpdateTaskStatusOutput struct {
 	Task repository.Task `json:"task"`
 }
 
-func (h *UpdateTaskStatusHandler) Handle(input UpdateTaskStatusInput) (UpdateTaskStatusOutput, error) {
+func (h *UpdateTaskStatusHandler) Handle(ctx context.Context, input UpdateTaskStatusInput) (output UpdateTaskStatusOutput, err error) {
+	ctx, span := h.tracer.Start(ctx, "UpdateTaskStatusHandler.Handle")
+	defer func() { endSpan(span, err) }()
+
+	scope, err := callerScope(ctx, h.users)
+	if err != nil {
+		return UpdateTaskStatusOutput{}, err
+	}
 
 	taskStatus := repository.TaskStatus(input.Status)
 
 	// Validate status value
-	if taskStatus != repository.TaskStatusPending && taskStatus != repository.TaskStatusCompleted {
-		return UpdateTaskStatusOutput{}, fmt.Errorf("Invalid status value. Must be either 'pending' or 'completed'")
+	if !taskStatus.Valid() {
+		return UpdateTaskStatusOutput{}, fmt.Errorf("%w: %q, must be one of %v", repository.ErrInvalidStatus, taskStatus, repository.TaskStatuses)
 	}
 
-	// Update the task status using repository
-	updatedTask, err := h.repository.UpdateTaskStatus(input.TaskID, taskStatus)
+	// Check and update the task in one transaction so it cannot change in between
+	var updatedTask repository.Task
+	err = h.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Look the task up across owners to tell forbidden updates from missing tasks
+		scope.AllOwners = true
+		task, err := h.repository.GetTaskById(ctx, scope, input.TaskID)
+		if err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		owned := task.OwnerID != nil && *task.OwnerID == scope.OwnerID
+		if err := authorize(ctx, h.authorizer, authz.ActionUpdateTask, authz.Resource{Owned: owned}); err != nil {
+			return err
+		}
+
+		// Only move along the declared transitions; concurrent status changes make the transaction retry
+		if err := task.Status.Transition(taskStatus); err != nil {
+			return err
+		}
+
+		// Update the task status using repository
+		updatedTask, err = h.repository.UpdateTaskStatusIfVersion(ctx, scope, input.TaskID, taskStatus, input.Version)
+		if err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		_, err = h.history.RecordTaskEvent(ctx, scope, repository.TaskEvent{
+			TaskID: updatedTask.ID,
+			Type:   repository.TaskEventStatusChanged,
+			Actor:  callerActor(ctx),
+			Before: map[string]any{"status": task.Status},
+			After:  map[string]any{"status": updatedTask.Status},
+		})
+		if err != nil {
+			return err
+		}
+
+		return h.publisher.Publish(ctx, EventTaskStatusChanged, TaskStatusChanged{Task: updatedTask, PreviousStatus: task.Status, Actor: callerActor(ctx)})
+	})
 	if err != nil {
-		return UpdateTaskStatusOutput{}, fmt.Errorf("failed to update task: %w", err)
+		return UpdateTaskStatusOutput{}, err
 	}
+	logging.FromContext(ctx).Info("Task status updated", slog.Int("task_id", updatedTask.ID), slog.String("status", string(updatedTask.Status)))
 
 	return UpdateTaskStatusOutput{
 		Task: updatedTask,
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/authz"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/etag"
+	"github.com/sumup/dependency-injection-go/internal/events"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/handlers"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/dig"
+)
+
+// memoryRepository keeps tasks in memory and checks versions like the Postgres repository
+type memoryRepository struct {
+	repository.IRepository
+	mutex sync.Mutex
+	tasks map[int]repository.Task
+}
+
+func (r *memoryRepository) GetTaskById(ctx context.Context, scope repository.Scope, id int) (repository.Task, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return repository.Task{}, fmt.Errorf("%w: %d", repository.ErrTaskNotFound, id)
+	}
+	return task, nil
+}
+
+func (r *memoryRepository) GetAllTasks(ctx context.Context, scope repository.Scope) ([]repository.Task, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var tasks []repository.Task
+	for id := 1; id <= len(r.tasks); id++ {
+		task := r.tasks[id]
+		if scope.AllOwners || (task.OwnerID != nil && *task.OwnerID == scope.OwnerID) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryRepository) CreateTask(ctx context.Context, scope repository.Scope, task repository.Task) (repository.Task, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task.ID = len(r.tasks) + 1
+	task.Version = 1
+	r.tasks[task.ID] = task
+	return task, nil
+}
+
+func (r *memoryRepository) UpdateTaskStatusIfVersion(ctx context.Context, scope repository.Scope, id int, status repository.TaskStatus, version int) (repository.Task, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return repository.Task{}, fmt.Errorf("%w: %d", repository.ErrTaskNotFound, id)
+	}
+	if version != 0 && task.Version != version {
+		return repository.Task{}, fmt.Errorf("%w: task %d has version %d, not %d", repository.ErrVersionConflict, id, task.Version, version)
+	}
+	task.Status = status
+	task.Version++
+	r.tasks[id] = task
+	return task, nil
+}
+
+// memoryHistory discards task events
+type memoryHistory struct {
+	repository.ITaskEventRepository
+}
+
+func (h memoryHistory) RecordTaskEvent(ctx context.Context, scope repository.Scope, event repository.TaskEvent) (repository.TaskEvent, error) {
+	return event, nil
+}
+
+// memoryUsers knows users who are members of the default workspace
+type memoryUsers map[string]int
+
+func (u memoryUsers) GetUserByUsername(ctx context.Context, username string) (repository.User, error) {
+	id, ok := u[username]
+	if !ok {
+		return repository.User{}, fmt.Errorf("%w: %s", repository.ErrUserNotFound, username)
+	}
+	return repository.User{ID: id, Username: username}, nil
+}
+
+func (u memoryUsers) GetWorkspaceIDs(ctx context.Context, userID int) ([]int, error) {
+	return []int{1}, nil
+}
+
+// memoryAPIKeys keeps API keys in memory
+type memoryAPIKeys map[string]auth.APIKey
+
+func (k memoryAPIKeys) GetAPIKeyByPrefix(ctx context.Context, prefix string) (auth.APIKey, error) {
+	key, ok := k[prefix]
+	if !ok {
+		return auth.APIKey{}, fmt.Errorf("%w: unknown key", auth.ErrInvalidAPIKey)
+	}
+	return key, nil
+}
+
+func (k memoryAPIKeys) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	return nil
+}
+
+// immediateTxManager runs units of work without a transaction
+type immediateTxManager struct{}
+
+func (immediateTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// discardingPublisher drops domain events
+type discardingPublisher struct{}
+
+func (discardingPublisher) Publish(ctx context.Context, eventType string, payload any) error {
+	return nil
+}
+
+func TestTaskVersions(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	container := dig.New()
+	providers := []any{
+		func() database.TxManager { return immediateTxManager{} },
+		func() repository.IRepository { return &memoryRepository{tasks: map[int]repository.Task{}} },
+		func() repository.ITaskEventRepository { return memoryHistory{} },
+		func() repository.IUserRepository { return nil },
+		func() events.Publisher { return discardingPublisher{} },
+		func() authz.Authorizer { return nil },
+		func() trace.TracerProvider { return noop.NewTracerProvider() },
+		handlers.NewCreateTaskHandler,
+		handlers.NewUpdateTaskStatusHandler,
+	}
+	for _, provider := range providers {
+		require.NoError(t, container.Provide(provider))
+	}
+
+	router := gin.New()
+	router.Use(ContainerMiddleware(container))
+	router.POST("/tasks", handleCreateTask)
+	router.POST("/tasks/:id", handleUpdateTaskStatus)
+
+	serve := func(path, body, ifMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			request.Header.Set(etag.IfMatchHeader, ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Created tasks carry their version
+	response := serve("/tasks", `{"title":"Versioned"}`, "")
+	require.Equal(t, http.StatusCreated, response.Code)
+	require.Equal(t, `"1"`, response.Header().Get(etag.Header))
+
+	// Writes with the current version bump it
+	response = serve("/tasks/1", `{"status":"in_progress"}`, `"1"`)
+	require.Equal(t, http.StatusCreated, response.Code)
+	require.Equal(t, `"2"`, response.Header().Get(etag.Header))
+
+	// Writes with a stale version fail their precondition
+	response = serve("/tasks/1", `{"status":"completed"}`, `"1"`)
+	require.Equal(t, http.StatusPreconditionFailed, response.Code)
+	require.Empty(t, response.Header().Get(etag.Header))
+
+	// Missing tasks are not found, whatever version is expected
+	response = serve("/tasks/2", `{"status":"completed"}`, `"1"`)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	// Writes without If-Match update any version
+	response = serve("/tasks/1", `{"status":"completed"}`, "")
+	require.Equal(t, http.StatusCreated, response.Code)
+	require.Equal(t, `"3"`, response.Header().Get(etag.Header))
+}
+
+func TestAPIKeyRoles(t *testing.T) {
+	// Create dependencies
+	gin.SetMode(gin.TestMode)
+	adaID, mikeID := 1, 2
+	tasks := &memoryRepository{tasks: map[int]repository.Task{
+		1: {ID: 1, Title: "Ada's", OwnerID: &adaID},
+		2: {ID: 2, Title: "Mike's", OwnerID: &mikeID},
+	}}
+	authorizer, err := authz.NewPolicyAuthorizer(config.Default().Authz)
+	require.NoError(t, err)
+
+	adminKey, adminPrefix, adminHash := auth.GenerateAPIKey()
+	memberKey, memberPrefix, memberHash := auth.GenerateAPIKey()
+	keys := memoryAPIKeys{
+		adminPrefix:  {ID: 1, Username: "ada", Scopes: []string{"tasks:read"}, Roles: []string{"admin"}, Hash: adminHash},
+		memberPrefix: {ID: 2, Username: "ada", Scopes: []string{"tasks:read"}, Hash: memberHash},
+	}
+
+	container := dig.New()
+	providers := []any{
+		func() repository.IRepository { return tasks },
+		func() repository.IUserRepository { return memoryUsers{"ada": adaID, "mike": mikeID} },
+		func() authz.Authorizer { return authorizer },
+		func() trace.TracerProvider { return noop.NewTracerProvider() },
+		handlers.NewGetTasksHandler,
+	}
+	for _, provider := range providers {
+		require.NoError(t, container.Provide(provider))
+	}
+
+	router := gin.New()
+	router.Use(auth.GinMiddleware(nil, auth.NewAPIKeyAuthenticator(keys)))
+	router.Use(ContainerMiddleware(container))
+	router.GET("/tasks", handleGetTasks)
+
+	list := func(key string) []int {
+		request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		request.Header.Set("Authorization", "ApiKey "+key)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var output handlers.GetTasksOutput
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &output))
+		var ids []int
+		for _, task := range output.Tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	// Keys with the admin role list every task of the workspace, within their read scope
+	require.Equal(t, []int{1, 2}, list(adminKey))
+
+	// Keys without roles get the default role and only list their user's tasks
+	require.Equal(t, []int{1}, list(memberKey))
+}
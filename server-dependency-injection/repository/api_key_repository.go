@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/auth"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+// lastUsedResolution bounds how often last_used_at is written for a busy key
+const lastUsedResolution = time.Minute
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) auth.APIKeyStore {
+	repository := APIKeyRepository{
+		pool: pool,
+	}
+
+	return &repository
+}
+
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (auth.APIKey, error) {
+	query := `
+		SELECT k.id, u.username, k.workspace_id, k.scopes, k.roles, k.key_hash, k.expires_at, k.revoked_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = $1`
+	var key auth.APIKey
+	err := r.pool.QueryRow(ctx, requestid.SQLComment(ctx, query), prefix).Scan(&key.ID, &key.Username, &key.WorkspaceID, &key.Scopes, &key.Roles, &key.Hash, &key.ExpiresAt, &key.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.APIKey{}, fmt.Errorf("%w: unknown key", auth.ErrInvalidAPIKey)
+	}
+	if err != nil {
+		return auth.APIKey{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`
+	_, err := r.pool.Exec(ctx, requestid.SQLComment(ctx, query), id, usedAt, usedAt.Add(-lastUsedResolution))
+	if err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
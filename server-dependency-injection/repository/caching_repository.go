@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sumup/dependency-injection-go/internal/cache"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
+
+// taskCacheKey identifies a read. The whole scope is part of the key, so a result is only
+// served to callers who would have read the same rows.
+type taskCacheKey struct {
+	method string
+	scope  Scope
+	id     int
+}
+
+// TaskCache keeps the results of task reads until a write to their workspace or their TTL
+type TaskCache struct {
+	entries *cache.LRU[taskCacheKey, any]
+	metrics *metrics.Metrics
+}
+
+func NewTaskCache(cfg config.CacheConfig, metrics *metrics.Metrics) *TaskCache {
+	taskCache := TaskCache{
+		entries: cache.NewLRU[taskCacheKey, any](cfg.Size, cfg.TTL),
+		metrics: metrics,
+	}
+
+	return &taskCache
+}
+
+// InvalidateWorkspace drops every read of the workspace, since any write may change the lists of all its scopes
+func (c *TaskCache) InvalidateWorkspace(workspaceID int) {
+	c.entries.DeleteFunc(func(key taskCacheKey) bool {
+		return key.scope.WorkspaceID == workspaceID
+	})
+}
+
+// Clear drops every read, after invalidations may have been missed
+func (c *TaskCache) Clear() {
+	c.entries.Clear()
+}
+
+func (c *TaskCache) get(key taskCacheKey) (any, bool) {
+	value, ok := c.entries.Get(key)
+	c.metrics.ObserveCacheLookup(key.method, ok)
+	return value, ok
+}
+
+// CachingRepository serves repeated reads from the cache and invalidates it once its writes
+// have committed, see database.AfterCommit. The change feed invalidates on the writes of other
+// instances; without it the TTL bounds how long their changes are hidden.
+type CachingRepository struct {
+	repository IRepository
+	cache      *TaskCache
+}
+
+func NewCachingRepository(repository IRepository, cache *TaskCache) IRepository {
+	caching := CachingRepository{
+		repository: repository,
+		cache:      cache,
+	}
+
+	return &caching
+}
+
+func (r *CachingRepository) GetTaskById(ctx context.Context, scope Scope, id int) (Task, error) {
+	// Units of work read their own writes and must not act on stale tasks
+	if _, ok := database.TxFromContext(ctx); ok {
+		return r.repository.GetTaskById(ctx, scope, id)
+	}
+
+	key := taskCacheKey{method: "GetTaskById", scope: scope, id: id}
+	if cached, ok := r.cache.get(key); ok {
+		return cached.(Task), nil
+	}
+
+	task, err := r.repository.GetTaskById(ctx, scope, id)
+	if err != nil {
+		return Task{}, err
+	}
+	r.cache.entries.Set(key, task)
+	return task, nil
+}
+
+func (r *CachingRepository) GetAllTasks(ctx context.Context, scope Scope) ([]Task, error) {
+	if _, ok := database.TxFromContext(ctx); ok {
+		return r.repository.GetAllTasks(ctx, scope)
+	}
+
+	// Callers get copies, so they cannot change the cached list
+	key := taskCacheKey{method: "GetAllTasks", scope: scope}
+	if cached, ok := r.cache.get(key); ok {
+		return slices.Clone(cached.([]Task)), nil
+	}
+
+	tasks, err := r.repository.GetAllTasks(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.entries.Set(key, slices.Clone(tasks))
+	return tasks, nil
+}
+
+func (r *CachingRepository) CreateTask(ctx context.Context, scope Scope, task Task) (Task, error) {
+	defer r.invalidate(ctx, scope)
+	return r.repository.CreateTask(ctx, scope, task)
+}
+
+func (r *CachingRepository) UpdateTaskStatus(ctx context.Context, scope Scope, id int, status TaskStatus) (Task, error) {
+	defer r.invalidate(ctx, scope)
+	return r.repository.UpdateTaskStatus(ctx, scope, id, status)
+}
+
+func (r *CachingRepository) UpdateTaskStatusIfVersion(ctx context.Context, scope Scope, id int, status TaskStatus, version int) (Task, error) {
+	defer r.invalidate(ctx, scope)
+	return r.repository.UpdateTaskStatusIfVersion(ctx, scope, id, status, version)
+}
+
+// invalidate forgets the cached reads of the workspace once the write is visible to other readers.
+// Inside a unit of work that is when it commits: invalidating earlier would let a concurrent read
+// cache the rows from before the write again.
+func (r *CachingRepository) invalidate(ctx context.Context, scope Scope) {
+	database.AfterCommit(ctx, func() {
+		r.cache.InvalidateWorkspace(scope.WorkspaceID)
+	})
+}
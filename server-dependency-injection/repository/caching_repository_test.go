@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+)
+
+// countingRepository counts the reads that reach it
+type countingRepository struct {
+	repository.IRepository
+	reads int
+	tasks []repository.Task
+}
+
+func (r *countingRepository) GetAllTasks(ctx context.Context, scope repository.Scope) ([]repository.Task, error) {
+	r.reads++
+	return append([]repository.Task{}, r.tasks...), nil
+}
+
+func (r *countingRepository) GetTaskById(ctx context.Context, scope repository.Scope, id int) (repository.Task, error) {
+	r.reads++
+	return r.tasks[0], nil
+}
+
+func (r *countingRepository) CreateTask(ctx context.Context, scope repository.Scope, task repository.Task) (repository.Task, error) {
+	r.tasks = append(r.tasks, task)
+	return task, nil
+}
+
+// tx marks a context as being in a unit of work
+type tx struct {
+	pgx.Tx
+}
+
+func TestCachingRepository(t *testing.T) {
+	// Create dependencies
+	ctx := context.Background()
+	inner := &countingRepository{tasks: []repository.Task{{ID: 1, Title: "First", WorkspaceID: 1}}}
+	taskCache := repository.NewTaskCache(config.CacheConfig{Size: 10, TTL: time.Minute}, metrics.New())
+	caching := repository.NewCachingRepository(inner, taskCache)
+	alice := repository.Scope{OwnerID: 1, WorkspaceID: 1}
+	bob := repository.Scope{OwnerID: 2, WorkspaceID: 1}
+	other := repository.Scope{OwnerID: 1, WorkspaceID: 2}
+
+	// Repeated reads of the same scope are served from the cache
+	tasks, err := caching.GetAllTasks(ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	_, err = caching.GetAllTasks(ctx, alice)
+	require.NoError(t, err)
+	require.Equal(t, 1, inner.reads)
+
+	// Callers cannot change the cached list
+	tasks[0].Title = "Changed"
+	tasks, _ = caching.GetAllTasks(ctx, alice)
+	require.Equal(t, "First", tasks[0].Title)
+
+	// Other scopes read their own rows
+	_, _ = caching.GetAllTasks(ctx, bob)
+	_, _ = caching.GetAllTasks(ctx, other)
+	require.Equal(t, 3, inner.reads)
+
+	// Units of work bypass the cache
+	_, _ = caching.GetAllTasks(database.WithTx(ctx, tx{}), alice)
+	require.Equal(t, 4, inner.reads)
+
+	// Writes invalidate every read of their workspace only
+	_, err = caching.CreateTask(ctx, bob, repository.Task{ID: 2, Title: "Second", WorkspaceID: 1})
+	require.NoError(t, err)
+	tasks, _ = caching.GetAllTasks(ctx, alice)
+	require.Len(t, tasks, 2)
+	_, _ = caching.GetAllTasks(ctx, bob)
+	_, _ = caching.GetAllTasks(ctx, other)
+	require.Equal(t, 6, inner.reads)
+
+	// Changes notified by other instances invalidate as well
+	_, _ = caching.GetTaskById(ctx, alice, 1)
+	_, _ = caching.GetTaskById(ctx, alice, 1)
+	require.Equal(t, 7, inner.reads)
+	taskCache.InvalidateWorkspace(1)
+	_, _ = caching.GetTaskById(ctx, alice, 1)
+	require.Equal(t, 8, inner.reads)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
+
+// InstrumentedRepository records the latency and errors of every call to the wrapped repository
+type InstrumentedRepository struct {
+	repository IRepository
+	metrics    *metrics.Metrics
+}
+
+func NewInstrumentedRepository(repository IRepository, metrics *metrics.Metrics) IRepository {
+	instrumented := InstrumentedRepository{
+		repository: repository,
+		metrics:    metrics,
+	}
+
+	return &instrumented
+}
+
+func (r *InstrumentedRepository) GetTaskById(ctx context.Context, scope Scope, id int) (task Task, err error) {
+	defer r.observe("GetTaskById", time.Now(), &err)
+	return r.repository.GetTaskById(ctx, scope, id)
+}
+
+func (r *InstrumentedRepository) CreateTask(ctx context.Context, scope Scope, task Task) (created Task, err error) {
+	defer r.observe("CreateTask", time.Now(), &err)
+	return r.repository.CreateTask(ctx, scope, task)
+}
+
+func (r *InstrumentedRepository) UpdateTaskStatus(ctx context.Context, scope Scope, id int, status TaskStatus) (updated Task, err error) {
+	defer r.observe("UpdateTaskStatus", time.Now(), &err)
+	return r.repository.UpdateTaskStatus(ctx, scope, id, status)
+}
+
+func (r *InstrumentedRepository) UpdateTaskStatusIfVersion(ctx context.Context, scope Scope, id int, status TaskStatus, version int) (updated Task, err error) {
+	defer r.observe("UpdateTaskStatusIfVersion", time.Now(), &err)
+	return r.repository.UpdateTaskStatusIfVersion(ctx, scope, id, status, version)
+}
+
+func (r *InstrumentedRepository) GetAllTasks(ctx context.Context, scope Scope) (tasks []Task, err error) {
+	defer r.observe("GetAllTasks", time.Now(), &err)
+	return r.repository.GetAllTasks(ctx, scope)
+}
+
+func (r *InstrumentedRepository) observe(method string, start time.Time, err *error) {
+	r.metrics.ObserveRepositoryCall(method, start, *err)
+}
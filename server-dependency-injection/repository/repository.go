@@ -2,17 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/logging"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
 )
 
+var (
+	// ErrTaskNotFound is returned when a task does not exist or is outside of the caller's scope
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrVersionConflict is returned when a task changed since the caller read the expected version
+	ErrVersionConflict = errors.New("task version conflict")
+)
+
+// taskColumns are selected and returned by every task query, in the order scanTask expects
+const taskColumns = `id, title, description, status, owner_id, workspace_id, created_at, updated_at, version`
+
 type IRepository interface {
-	GetTaskById(id int) (Task, error)
-	CreateTask(task Task) (Task, error)
-	UpdateTaskStatus(id int, status TaskStatus) (Task, error)
-	GetAllTasks() ([]Task, error)
+	GetTaskById(ctx context.Context, scope Scope, id int) (Task, error)
+	CreateTask(ctx context.Context, scope Scope, task Task) (Task, error)
+	UpdateTaskStatus(ctx context.Context, scope Scope, id int, status TaskStatus) (Task, error)
+	// UpdateTaskStatusIfVersion updates the status only if the task still has the expected version
+	UpdateTaskStatusIfVersion(ctx context.Context, scope Scope, id int, status TaskStatus, version int) (Task, error)
+	GetAllTasks(ctx context.Context, scope Scope) ([]Task, error)
 }
 
 type Repository struct {
@@ -27,65 +46,135 @@ func NewRepository(pool *pgxpool.Pool) IRepository {
 	return &repository
 }
 
-func (r *Repository) GetTaskById(id int) (Task, error) {
+// inWorkspace runs fn in a transaction where Postgres only exposes the rows of the scope's workspace.
+// Within a unit of work the transaction is a savepoint of the ambient transaction.
+func inWorkspace(ctx context.Context, pool *pgxpool.Pool, scope Scope, fn func(tx pgx.Tx) error) error {
+	if scope.WorkspaceID == 0 {
+		return errors.New("no workspace selected")
+	}
+
+	_, nested := database.TxFromContext(ctx)
+	return pgx.BeginFunc(ctx, database.Conn(ctx, pool), func(tx pgx.Tx) error {
+		query := `SELECT set_config('role', $1, true), set_config('app.workspace_id', $2, true)`
+		if _, err := tx.Exec(ctx, requestid.SQLComment(ctx, query), database.AppRole, strconv.Itoa(scope.WorkspaceID)); err != nil {
+			return fmt.Errorf("failed to enter workspace: %w", err)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		// Settings survive the release of a savepoint, so restore the role for the rest of the unit of work
+		if nested {
+			if _, err := tx.Exec(ctx, requestid.SQLComment(ctx, `SELECT set_config('role', 'none', true)`)); err != nil {
+				return fmt.Errorf("failed to leave workspace: %w", err)
+			}
+		}
+		return nil
+	})
+}
 
-	query := `SELECT id, title, description, status, created_at, updated_at FROM tasks WHERE id = $1`
+func (r *Repository) GetTaskById(ctx context.Context, scope Scope, id int) (Task, error) {
 	var task Task
-	err := r.pool.QueryRow(context.Background(), query, id).Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt, &task.UpdatedAt)
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		var err error
+		task, err = getTask(ctx, tx, scope, id)
+		return err
+	})
+	return task, err
+}
+
+func getTask(ctx context.Context, tx pgx.Tx, scope Scope, id int) (Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND ($2 OR owner_id = $3)`
+	task, err := scanTask(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), id, scope.AllOwners, scope.OwnerID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Task{}, fmt.Errorf("%w: %d", ErrTaskNotFound, id)
+	}
 	if err != nil {
 		return Task{}, fmt.Errorf("failed to get task: %w", err)
 	}
 	return task, nil
 }
 
-func (r *Repository) CreateTask(task Task) (Task, error) {
+func (r *Repository) CreateTask(ctx context.Context, scope Scope, task Task) (Task, error) {
 
-	// Insert the task into the database
-	query := `INSERT INTO tasks (title, description, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	var id int
-	err := r.pool.QueryRow(context.Background(), query, task.Title, task.Description, task.Status, time.Now().UTC(), time.Now().UTC()).Scan(&id)
-	if err != nil {
-		return Task{}, fmt.Errorf("failed to create task: %w", err)
+	// The task belongs to the caller; anonymous callers create tasks without owner
+	var ownerID *int
+	if scope.OwnerID != 0 {
+		ownerID = &scope.OwnerID
 	}
 
-	// Get the created task
-	return r.GetTaskById(id)
+	var created Task
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		// Insert the task and return the stored row in the same statement
+		query := `INSERT INTO tasks (title, description, status, owner_id, workspace_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + taskColumns
+		var err error
+		created, err = scanTask(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), task.Title, task.Description, task.Status, ownerID, scope.WorkspaceID, time.Now().UTC(), time.Now().UTC()))
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		logging.FromContext(ctx).Debug("Inserted task", slog.Int("task_id", created.ID))
+		return nil
+	})
+	return created, err
 }
 
-func (r *Repository) UpdateTaskStatus(id int, status TaskStatus) (Task, error) {
-
-	query := `UPDATE tasks SET status = $1, updated_at = $2 WHERE id = $3 RETURNING id`
-	var taskId int
-	err := r.pool.QueryRow(context.Background(), query, status, time.Now().UTC(), id).Scan(&taskId)
-	if err != nil {
-		return Task{}, fmt.Errorf("failed to update task status: %w", err)
-	}
-
-	// Get the updated task
-	return r.GetTaskById(taskId)
+func (r *Repository) UpdateTaskStatus(ctx context.Context, scope Scope, id int, status TaskStatus) (Task, error) {
+	return r.UpdateTaskStatusIfVersion(ctx, scope, id, status, 0)
 }
 
-func (r *Repository) GetAllTasks() ([]Task, error) {
-	query := `SELECT id, title, description, status, created_at, updated_at FROM tasks ORDER BY id`
-	rows, err := r.pool.Query(context.Background(), query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
-	}
-	defer rows.Close()
+// UpdateTaskStatusIfVersion compares and sets the version; version 0 matches any version
+func (r *Repository) UpdateTaskStatusIfVersion(ctx context.Context, scope Scope, id int, status TaskStatus, version int) (Task, error) {
+	var updated Task
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `UPDATE tasks SET status = $1, updated_at = $2, version = version + 1 WHERE id = $3 AND ($4 OR owner_id = $5) AND ($6 = 0 OR version = $6) RETURNING ` + taskColumns
+		var err error
+		updated, err = scanTask(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), status, time.Now().UTC(), id, scope.AllOwners, scope.OwnerID, version))
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Tell a stale version from a missing task
+			current, err := getTask(ctx, tx, scope, id)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: task %d has version %d, not %d", ErrVersionConflict, id, current.Version, version)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+		logging.FromContext(ctx).Debug("Updated task status", slog.Int("task_id", updated.ID), slog.String("status", string(status)))
+		return nil
+	})
+	return updated, err
+}
 
+func (r *Repository) GetAllTasks(ctx context.Context, scope Scope) ([]Task, error) {
 	var tasks []Task
-	for rows.Next() {
-		var task Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt, &task.UpdatedAt)
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `SELECT ` + taskColumns + ` FROM tasks WHERE $1 OR owner_id = $2 ORDER BY id`
+		rows, err := tx.Query(ctx, requestid.SQLComment(ctx, query), scope.AllOwners, scope.OwnerID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+			return fmt.Errorf("failed to get tasks: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			task, err := scanTask(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan task: %w", err)
+			}
+			tasks = append(tasks, task)
 		}
-		tasks = append(tasks, task)
-	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over tasks: %w", err)
-	}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over tasks: %w", err)
+		}
+		return nil
+	})
+	return tasks, err
+}
 
-	return tasks, nil
+// scanTask reads a row of taskColumns
+func scanTask(row pgx.Row) (Task, error) {
+	var task Task
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.OwnerID, &task.WorkspaceID, &task.CreatedAt, &task.UpdatedAt, &task.Version)
+	return task, err
 }
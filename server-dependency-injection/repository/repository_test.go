@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/tenant"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+)
+
+func TestRepository_UpdateTaskStatusIfVersion(t *testing.T) {
+	// Set up database connection
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	pool, err := database.NewPool(context.Background(), cfg.Database)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	// Create dependencies
+	ctx := context.Background()
+	repo := repository.NewRepository(pool)
+	scope := repository.Scope{AllOwners: true, WorkspaceID: tenant.DefaultWorkspaceID}
+	created, err := repo.CreateTask(ctx, scope, repository.Task{Title: "Versioned", Status: repository.TaskStatusPending})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM tasks WHERE id = $1", created.ID)
+	})
+
+	// Writes with the current version bump it
+	updated, err := repo.UpdateTaskStatusIfVersion(ctx, scope, created.ID, repository.TaskStatusInProgress, created.Version)
+	require.NoError(t, err)
+	require.Equal(t, created.Version+1, updated.Version)
+	require.Equal(t, repository.TaskStatusInProgress, updated.Status)
+
+	// Writes with a stale version conflict and change nothing
+	_, err = repo.UpdateTaskStatusIfVersion(ctx, scope, created.ID, repository.TaskStatusCompleted, created.Version)
+	require.ErrorIs(t, err, repository.ErrVersionConflict)
+	current, err := repo.GetTaskById(ctx, scope, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated, current)
+
+	// Missing tasks are not found rather than in conflict
+	_, err = repo.UpdateTaskStatusIfVersion(ctx, scope, -1, repository.TaskStatusCompleted, 1)
+	require.ErrorIs(t, err, repository.ErrTaskNotFound)
+	require.NotErrorIs(t, err, repository.ErrVersionConflict)
+
+	// Version 0 updates any version
+	updated, err = repo.UpdateTaskStatusIfVersion(ctx, scope, created.ID, repository.TaskStatusCompleted, 0)
+	require.NoError(t, err)
+	require.Equal(t, created.Version+2, updated.Version)
+}
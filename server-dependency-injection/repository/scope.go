@@ -0,0 +1,11 @@
+package repository
+
+// Scope restricts a repository call to the tasks the caller may see and change
+type Scope struct {
+	// OwnerID is the user making the call, or 0 when the caller is anonymous
+	OwnerID int
+	// AllOwners lifts the ownership restriction, for admins
+	AllOwners bool
+	// WorkspaceID is the tenant every query is confined to by row-level security
+	WorkspaceID int
+}
@@ -1,6 +1,11 @@
 package repository
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
 
 // Task represents a task in our system
 type Task struct {
@@ -8,14 +13,76 @@ type Task struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      TaskStatus `json:"status"`
+	OwnerID     *int       `json:"ownerId"`
+	WorkspaceID int        `json:"workspaceId"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
+	// Version is bumped by every write and exposed as the ETag of the task
+	Version int `json:"version"`
 }
 
 // TaskStatus represents the possible status values for a task
 type TaskStatus string
 
 const (
-	TaskStatusPending   TaskStatus = "pending"
-	TaskStatusCompleted TaskStatus = "completed"
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusBlocked    TaskStatus = "blocked"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+)
+
+var (
+	// ErrInvalidStatus is returned for values that are not a TaskStatus
+	ErrInvalidStatus = errors.New("invalid task status")
+	// ErrInvalidTransition is returned when a task cannot move from its status to the requested one
+	ErrInvalidTransition = errors.New("invalid task status transition")
 )
+
+// TaskStatuses lists every status, in the order a task usually goes through them.
+// The tasks_status_check constraint allows the same values.
+var TaskStatuses = []TaskStatus{TaskStatusPending, TaskStatusInProgress, TaskStatusBlocked, TaskStatusCompleted, TaskStatusCancelled}
+
+// Transitions lists the statuses a task may move to from each status.
+// Completed and cancelled tasks have to be reopened before they can move on.
+var Transitions = map[TaskStatus][]TaskStatus{
+	TaskStatusPending:    {TaskStatusInProgress, TaskStatusBlocked, TaskStatusCompleted, TaskStatusCancelled},
+	TaskStatusInProgress: {TaskStatusPending, TaskStatusBlocked, TaskStatusCompleted, TaskStatusCancelled},
+	TaskStatusBlocked:    {TaskStatusPending, TaskStatusInProgress, TaskStatusCancelled},
+	TaskStatusCompleted:  {TaskStatusPending},
+	TaskStatusCancelled:  {TaskStatusPending},
+}
+
+// Valid reports whether the status is one of TaskStatuses
+func (s TaskStatus) Valid() bool {
+	return slices.Contains(TaskStatuses, s)
+}
+
+// CanTransitionTo reports whether a task may move from the status to next.
+// Keeping the current status is always allowed, so repeated updates are harmless.
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	return s == next || slices.Contains(Transitions[s], next)
+}
+
+// TransitionError describes a rejected status change and the statuses that are allowed instead
+type TransitionError struct {
+	From    TaskStatus
+	To      TaskStatus
+	Allowed []TaskStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%v: cannot move task from %s to %s, allowed are %v", ErrInvalidTransition, e.From, e.To, e.Allowed)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Transition returns a TransitionError unless a task may move from the status to next
+func (s TaskStatus) Transition(next TaskStatus) error {
+	if !s.CanTransitionTo(next) {
+		return &TransitionError{From: s, To: next, Allowed: Transitions[s]}
+	}
+	return nil
+}
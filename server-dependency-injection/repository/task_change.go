@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TaskChangesChannel is notified by the tasks table on every write, see notify_task_change
+const TaskChangesChannel = "task_changes"
+
+// TaskChangeOp is the kind of write a task change records
+type TaskChangeOp string
+
+const (
+	TaskChangeInsert TaskChangeOp = "INSERT"
+	TaskChangeUpdate TaskChangeOp = "UPDATE"
+	TaskChangeDelete TaskChangeOp = "DELETE"
+)
+
+// TaskChange identifies a written task. It carries no task data: readers load the task if they need it.
+type TaskChange struct {
+	// ChangeID is unique across instances; changes are notified in commit order, which need not follow it
+	ChangeID    int64        `json:"changeId"`
+	Op          TaskChangeOp `json:"op"`
+	TaskID      int          `json:"id"`
+	WorkspaceID int          `json:"workspaceId"`
+	OwnerID     *int         `json:"ownerId"`
+	Version     int          `json:"version"`
+}
+
+// ParseTaskChange decodes the payload of a TaskChangesChannel notification
+func ParseTaskChange(payload string) (TaskChange, error) {
+	var change TaskChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return TaskChange{}, fmt.Errorf("failed to decode task change: %w", err)
+	}
+	return change, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import "time"
+
+// TaskEventType is the kind of change a task event records
+type TaskEventType string
+
+const (
+	TaskEventCreated       TaskEventType = "created"
+	TaskEventStatusChanged TaskEventType = "status_changed"
+)
+
+// TaskEvent records a change to a task, who made it and when
+type TaskEvent struct {
+	ID          int64         `json:"id"`
+	TaskID      int           `json:"taskId"`
+	WorkspaceID int           `json:"workspaceId"`
+	Type        TaskEventType `json:"type"`
+	// Actor is the subject of the caller, nil for anonymous callers
+	Actor *string `json:"actor"`
+	// Before and After hold the fields of the task the change affected
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// TaskEventFilter selects events of the audit feed; zero fields do not filter
+type TaskEventFilter struct {
+	TaskID int
+	Actor  string
+	Type   TaskEventType
+	Since  *time.Time
+	Until  *time.Time
+	// BeforeID pages through the feed, newest first
+	BeforeID int64
+	Limit    int
+}
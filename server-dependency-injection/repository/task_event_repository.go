@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+// taskEventColumns are selected by every task event query, in the order scanTaskEvent expects
+const taskEventColumns = `id, task_id, workspace_id, type, actor, before, after, created_at`
+
+type ITaskEventRepository interface {
+	// RecordTaskEvent appends an event; call it in the unit of work of the change it records
+	RecordTaskEvent(ctx context.Context, scope Scope, event TaskEvent) (TaskEvent, error)
+	// GetTaskHistory returns the events of a task, oldest first
+	GetTaskHistory(ctx context.Context, scope Scope, taskID int) ([]TaskEvent, error)
+	// GetTaskEvents returns the events of the workspace matching the filter, newest first
+	GetTaskEvents(ctx context.Context, scope Scope, filter TaskEventFilter) ([]TaskEvent, error)
+}
+
+type TaskEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTaskEventRepository(pool *pgxpool.Pool) ITaskEventRepository {
+	repository := TaskEventRepository{
+		pool: pool,
+	}
+
+	return &repository
+}
+
+func (r *TaskEventRepository) RecordTaskEvent(ctx context.Context, scope Scope, event TaskEvent) (TaskEvent, error) {
+	var recorded TaskEvent
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `INSERT INTO task_events (task_id, workspace_id, type, actor, before, after) VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + taskEventColumns
+		var err error
+		recorded, err = scanTaskEvent(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), event.TaskID, scope.WorkspaceID, event.Type, event.Actor, event.Before, event.After))
+		if err != nil {
+			return fmt.Errorf("failed to record task event: %w", err)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+func (r *TaskEventRepository) GetTaskHistory(ctx context.Context, scope Scope, taskID int) ([]TaskEvent, error) {
+	var events []TaskEvent
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `SELECT ` + taskEventColumns + ` FROM task_events WHERE task_id = $1 ORDER BY id`
+		var err error
+		events, err = queryTaskEvents(ctx, tx, query, taskID)
+		return err
+	})
+	return events, err
+}
+
+func (r *TaskEventRepository) GetTaskEvents(ctx context.Context, scope Scope, filter TaskEventFilter) ([]TaskEvent, error) {
+	var events []TaskEvent
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `
+			SELECT ` + taskEventColumns + ` FROM task_events
+			WHERE ($1 = 0 OR task_id = $1)
+				AND ($2 = '' OR actor = $2)
+				AND ($3 = '' OR type = $3)
+				AND ($4::TIMESTAMPTZ IS NULL OR created_at >= $4)
+				AND ($5::TIMESTAMPTZ IS NULL OR created_at < $5)
+				AND ($6::BIGINT = 0 OR id < $6)
+			ORDER BY id DESC
+			LIMIT $7`
+		var err error
+		events, err = queryTaskEvents(ctx, tx, query, filter.TaskID, filter.Actor, filter.Type, filter.Since, filter.Until, filter.BeforeID, filter.Limit)
+		return err
+	})
+	return events, err
+}
+
+func queryTaskEvents(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]TaskEvent, error) {
+	rows, err := tx.Query(ctx, requestid.SQLComment(ctx, query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []TaskEvent{}
+	for rows.Next() {
+		event, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over task events: %w", err)
+	}
+	return events, nil
+}
+
+// scanTaskEvent reads a row of taskEventColumns
+func scanTaskEvent(row pgx.Row) (TaskEvent, error) {
+	var event TaskEvent
+	err := row.Scan(&event.ID, &event.TaskID, &event.WorkspaceID, &event.Type, &event.Actor, &event.Before, &event.After, &event.CreatedAt)
+	return event, err
+}
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/server-dependency-injection/repository"
+)
+
+func TestTransitions(t *testing.T) {
+	// Every status declares its transitions, and only to known statuses
+	require.Len(t, repository.Transitions, len(repository.TaskStatuses))
+	for _, status := range repository.TaskStatuses {
+		for _, next := range repository.Transitions[status] {
+			require.True(t, next.Valid(), "%s -> %s", status, next)
+		}
+	}
+	require.False(t, repository.TaskStatus("done").Valid())
+}
+
+func TestTaskStatus_Transition(t *testing.T) {
+	// Declared transitions and keeping the status are allowed
+	require.NoError(t, repository.TaskStatusPending.Transition(repository.TaskStatusInProgress))
+	require.NoError(t, repository.TaskStatusCompleted.Transition(repository.TaskStatusCompleted))
+
+	// Cancelled tasks have to be reopened before they can be completed
+	err := repository.TaskStatusCancelled.Transition(repository.TaskStatusCompleted)
+	require.ErrorIs(t, err, repository.ErrInvalidTransition)
+	var transitionErr *repository.TransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, []repository.TaskStatus{repository.TaskStatusPending}, transitionErr.Allowed)
+
+	require.NoError(t, repository.TaskStatusCancelled.Transition(repository.TaskStatusPending))
+	require.NoError(t, repository.TaskStatusPending.Transition(repository.TaskStatusCompleted))
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "time"
+
+// User is a registered caller; the username matches the subject of their token
+type User struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
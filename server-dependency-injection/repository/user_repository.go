@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+// ErrUserNotFound is returned when no user has the requested username
+var ErrUserNotFound = errors.New("user not found")
+
+type IUserRepository interface {
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetWorkspaceIDs(ctx context.Context, userID int) ([]int, error)
+}
+
+type UserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepository(pool *pgxpool.Pool) IUserRepository {
+	repository := UserRepository{
+		pool: pool,
+	}
+
+	return &repository
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	query := `SELECT id, username, created_at FROM users WHERE username = $1`
+	var user User
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, requestid.SQLComment(ctx, query), username).Scan(&user.ID, &user.Username, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return User{}, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetWorkspaceIDs(ctx context.Context, userID int) ([]int, error) {
+	query := `SELECT workspace_id FROM workspace_members WHERE user_id = $1 ORDER BY workspace_id`
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, requestid.SQLComment(ctx, query), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces: %w", err)
+	}
+
+	workspaceIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces: %w", err)
+	}
+	return workspaceIDs, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import "time"
+
+// Webhook is an endpoint receiving the task events of its workspace
+type Webhook struct {
+	ID          int      `json:"id"`
+	WorkspaceID int      `json:"workspaceId"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes"`
+	// Secret signs the deliveries; it is only returned when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDeliveryStatus is the state of a delivery in the delivery log
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed deliveries ran out of attempts; they are only retried when redelivered
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an entry of the delivery log: an event sent, or to be sent, to a webhook
+type WebhookDelivery struct {
+	ID        int64                 `json:"id"`
+	WebhookID int                   `json:"webhookId"`
+	EventID   int64                 `json:"eventId"`
+	EventType string                `json:"eventType"`
+	Status    WebhookDeliveryStatus `json:"status"`
+	Attempts  int                   `json:"attempts"`
+	// ResponseStatus and LastError describe the latest attempt
+	ResponseStatus *int       `json:"responseStatus"`
+	LastError      *string    `json:"lastError"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+}
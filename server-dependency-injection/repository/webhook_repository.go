@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sumup/dependency-injection-go/internal/requestid"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook does not exist or belongs to another workspace
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a delivery does not exist or belongs to another webhook
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const (
+	// webhookColumns are selected by every webhook query, in the order scanWebhook expects
+	webhookColumns = `id, workspace_id, url, event_types, created_at`
+	// webhookDeliveryColumns are selected by every delivery query, in the order scanWebhookDelivery expects
+	webhookDeliveryColumns = `id, webhook_id, event_id, event_type, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at`
+)
+
+type IWebhookRepository interface {
+	CreateWebhook(ctx context.Context, scope Scope, webhook Webhook) (Webhook, error)
+	GetWebhooks(ctx context.Context, scope Scope) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, scope Scope, id int) error
+	// GetWebhookDeliveries returns the delivery log of a webhook, newest first
+	GetWebhookDeliveries(ctx context.Context, scope Scope, webhookID int, limit int) ([]WebhookDelivery, error)
+	// RedeliverWebhookDelivery schedules the delivery to be attempted again now, whatever its status
+	RedeliverWebhookDelivery(ctx context.Context, scope Scope, webhookID int, deliveryID int64) (WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) IWebhookRepository {
+	repository := WebhookRepository{
+		pool: pool,
+	}
+
+	return &repository
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, scope Scope, webhook Webhook) (Webhook, error) {
+	var created Webhook
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `INSERT INTO webhooks (workspace_id, url, event_types, secret) VALUES ($1, $2, $3, $4) RETURNING ` + webhookColumns
+		var err error
+		created, err = scanWebhook(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), scope.WorkspaceID, webhook.URL, webhook.EventTypes, webhook.Secret))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	created.Secret = webhook.Secret
+	return created, nil
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context, scope Scope) ([]Webhook, error) {
+	webhooks := []Webhook{}
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`
+		rows, err := tx.Query(ctx, requestid.SQLComment(ctx, query))
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			webhook, err := scanWebhook(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan webhook: %w", err)
+			}
+			webhooks = append(webhooks, webhook)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over webhooks: %w", err)
+		}
+		return nil
+	})
+	return webhooks, err
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, scope Scope, id int) error {
+	return inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, requestid.SQLComment(ctx, `DELETE FROM webhooks WHERE id = $1`), id)
+		if err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: %d", ErrWebhookNotFound, id)
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepository) GetWebhookDeliveries(ctx context.Context, scope Scope, webhookID int, limit int) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		// Tell a webhook without deliveries from one outside of the workspace
+		var exists bool
+		if err := tx.QueryRow(ctx, requestid.SQLComment(ctx, `SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1)`), webhookID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to get webhook: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: %d", ErrWebhookNotFound, webhookID)
+		}
+
+		query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+		rows, err := tx.Query(ctx, requestid.SQLComment(ctx, query), webhookID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook deliveries: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			delivery, err := scanWebhookDelivery(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan webhook delivery: %w", err)
+			}
+			deliveries = append(deliveries, delivery)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over webhook deliveries: %w", err)
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func (r *WebhookRepository) RedeliverWebhookDelivery(ctx context.Context, scope Scope, webhookID int, deliveryID int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := inWorkspace(ctx, r.pool, scope, func(tx pgx.Tx) error {
+		// Redeliveries get the full number of attempts again
+		query := `
+			UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+			WHERE id = $1 AND webhook_id = $2
+			RETURNING ` + webhookDeliveryColumns
+		var err error
+		delivery, err = scanWebhookDelivery(tx.QueryRow(ctx, requestid.SQLComment(ctx, query), deliveryID, webhookID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %d", ErrWebhookDeliveryNotFound, deliveryID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+		}
+		return nil
+	})
+	return delivery, err
+}
+
+// scanWebhook reads a row of webhookColumns
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var webhook Webhook
+	err := row.Scan(&webhook.ID, &webhook.WorkspaceID, &webhook.URL, &webhook.EventTypes, &webhook.CreatedAt)
+	return webhook, err
+}
+
+// scanWebhookDelivery reads a row of webhookDeliveryColumns
+func scanWebhookDelivery(row pgx.Row) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Status, &delivery.Attempts,
+		&delivery.ResponseStatus, &delivery.LastError, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt)
+	return delivery, err
+}
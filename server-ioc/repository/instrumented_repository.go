@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sumup/dependency-injection-go/internal/metrics"
+)
+
+// InstrumentedRepository records the latency and errors of every call to the wrapped repository
+type InstrumentedRepository struct {
+	repository IRepository
+	metrics    *metrics.Metrics
+}
+
+func NewInstrumentedRepository(repository IRepository, metrics *metrics.Metrics) IRepository {
+	instrumented := InstrumentedRepository{
+		repository: repository,
+		metrics:    metrics,
+	}
+
+	return &instrumented
+}
+
+func (r *InstrumentedRepository) GetTaskById(id int) (task Task, err error) {
+	defer r.observe("GetTaskById", time.Now(), &err)
+	return r.repository.GetTaskById(id)
+}
+
+func (r *InstrumentedRepository) CreateTask(task Task) (created Task, err error) {
+	defer r.observe("CreateTask", time.Now(), &err)
+	return r.repository.CreateTask(task)
+}
+
+func (r *InstrumentedRepository) UpdateTaskStatus(id int, status TaskStatus) (updated Task, err error) {
+	defer r.observe("UpdateTaskStatus", time.Now(), &err)
+	return r.repository.UpdateTaskStatus(id, status)
+}
+
+func (r *InstrumentedRepository) GetAllTasks() (tasks []Task, err error) {
+	defer r.observe("GetAllTasks", time.Now(), &err)
+	return r.repository.GetAllTasks()
+}
+
+func (r *InstrumentedRepository) observe(method string, start time.Time, err *error) {
+	r.metrics.ObserveRepositoryCall(method, start, *err)
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -39,29 +41,32 @@ func (r *Repository) GetTaskById(id int) (Task, error) {
 
 func (r *Repository) CreateTask(task Task) (Task, error) {
 
-	// Insert the task into the database
-	query := `INSERT INTO tasks (title, description, status) VALUES ($1, $2, $3) RETURNING id`
-	var id int
-	err := r.pool.QueryRow(context.Background(), query, task.Title, task.Description, task.Status).Scan(&id)
+	// Insert the task and return the stored row in the same statement
+	query := `INSERT INTO tasks (title, description, status) VALUES ($1, $2, $3) RETURNING id, title, description, status`
+	var created Task
+	err := r.pool.QueryRow(context.Background(), query, task.Title, task.Description, task.Status).Scan(&created.ID, &created.Title, &created.Description, &created.Status)
 	if err != nil {
 		return Task{}, fmt.Errorf("failed to create task: %w", err)
 	}
-
-	// Get the created task
-	return r.GetTaskById(id)
+	return created, nil
 }
 
 func (r *Repository) UpdateTaskStatus(id int, status TaskStatus) (Task, error) {
 
-	query := `UPDATE tasks SET status = $1 WHERE id = $2 RETURNING id`
-	var taskId int
-	err := r.pool.QueryRow(context.Background(), query, status, id).Scan(&taskId)
+	// Update the task only from a status that allows the change, and return the row as this statement left it
+	query := `UPDATE tasks SET status = $1, version = version + 1 WHERE id = $2 AND status = ANY($3) RETURNING id, title, description, status`
+	var updated Task
+	err := r.pool.QueryRow(context.Background(), query, status, id, previousStatuses[status]).Scan(&updated.ID, &updated.Title, &updated.Description, &updated.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Either the task does not exist or its status does not allow the change
+		if current, getErr := r.GetTaskById(id); getErr == nil {
+			return Task{}, fmt.Errorf("%w: cannot move task from %s to %s", ErrInvalidTransition, current.Status, status)
+		}
+	}
 	if err != nil {
 		return Task{}, fmt.Errorf("failed to update task status: %w", err)
 	}
-
-	// Get the updated task
-	return r.GetTaskById(taskId)
+	return updated, nil
 }
 
 func (r *Repository) GetAllTasks() ([]Task, error) {
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup/dependency-injection-go/internal/config"
+	"github.com/sumup/dependency-injection-go/internal/database"
+	"github.com/sumup/dependency-injection-go/server-ioc/repository"
+)
+
+// queryCounter counts the statements sent to the database, each one a round trip
+type queryCounter struct {
+	queries atomic.Int64
+}
+
+func (c *queryCounter) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	c.queries.Add(1)
+	return ctx
+}
+
+func (c *queryCounter) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+}
+
+// newBenchmarkPool connects to the configured database. It returns the title the benchmark must give
+// its tasks: only tasks with that title are deleted afterwards, the others may be the developer's.
+func newBenchmarkPool(b *testing.B) (*pgxpool.Pool, *queryCounter, string) {
+	cfg, err := config.Load()
+	require.NoError(b, err)
+	counter := &queryCounter{}
+	pool, err := database.NewPool(context.Background(), cfg.Database, database.WithQueryTracer(counter))
+	require.NoError(b, err)
+	title := fmt.Sprintf("Benchmark %d", time.Now().UnixNano())
+	b.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM tasks WHERE title = $1", title)
+		pool.Close()
+	})
+	return pool, counter, title
+}
+
+// reportRoundTrips reports the statements sent per operation since the benchmark timer was reset
+func reportRoundTrips(b *testing.B, counter *queryCounter, before int64) {
+	b.ReportMetric(float64(counter.queries.Load()-before)/float64(b.N), "roundtrips/op")
+}
+
+func BenchmarkCreateTask(b *testing.B) {
+	pool, counter, title := newBenchmarkPool(b)
+	repo := repository.NewRepository(pool)
+	task := repository.Task{Title: title, Description: "Created by a benchmark", Status: repository.TaskStatusPending}
+
+	b.Run("Returning", func(b *testing.B) {
+		before := counter.queries.Load()
+		for b.Loop() {
+			_, err := repo.CreateTask(task)
+			require.NoError(b, err)
+		}
+		reportRoundTrips(b, counter, before)
+	})
+
+	// The previous implementation inserted the task, then read it back
+	b.Run("InsertThenSelect", func(b *testing.B) {
+		before := counter.queries.Load()
+		for b.Loop() {
+			var id int
+			err := pool.QueryRow(context.Background(),
+				`INSERT INTO tasks (title, description, status) VALUES ($1, $2, $3) RETURNING id`,
+				task.Title, task.Description, task.Status).Scan(&id)
+			require.NoError(b, err)
+			_, err = repo.GetTaskById(id)
+			require.NoError(b, err)
+		}
+		reportRoundTrips(b, counter, before)
+	})
+}
+
+func BenchmarkUpdateTaskStatus(b *testing.B) {
+	pool, counter, title := newBenchmarkPool(b)
+	repo := repository.NewRepository(pool)
+	created, err := repo.CreateTask(repository.Task{Title: title, Status: repository.TaskStatusPending})
+	require.NoError(b, err)
+
+	b.Run("Returning", func(b *testing.B) {
+		before := counter.queries.Load()
+		for b.Loop() {
+			_, err := repo.UpdateTaskStatus(created.ID, repository.TaskStatusCompleted)
+			require.NoError(b, err)
+		}
+		reportRoundTrips(b, counter, before)
+	})
+
+	// The previous implementation updated the task, then read it back
+	b.Run("UpdateThenSelect", func(b *testing.B) {
+		before := counter.queries.Load()
+		for b.Loop() {
+			var id int
+			err := pool.QueryRow(context.Background(),
+				`UPDATE tasks SET status = $1, version = version + 1 WHERE id = $2 RETURNING id`,
+				repository.TaskStatusCompleted, created.ID).Scan(&id)
+			require.NoError(b, err)
+			_, err = repo.GetTaskById(id)
+			require.NoError(b, err)
+		}
+		reportRoundTrips(b, counter, before)
+	})
+}